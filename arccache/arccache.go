@@ -0,0 +1,323 @@
+/*
+Package arccache is a simple generic implementation of an ARC (Adaptive Replacement Cache).
+The cache keeps recently used entries seen once (T1) apart from entries seen at least twice (T2)
+and remembers the IDs of entries recently evicted from both lists in ghost lists (B1 and B2).
+Hits on the ghost lists adapt the target size of T1, so the cache balances itself between
+recency and frequency depending on the workload.
+*/
+package arccache
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type item[T any] struct {
+	id    string
+	value T
+}
+
+type entry struct {
+	element *list.Element
+	list    *list.List
+}
+
+type ARCCache[T any] struct {
+	t1            *list.List
+	t2            *list.List
+	b1            *list.List
+	b2            *list.List
+	index         map[string]entry
+	target        int
+	maxSize       int
+	hitsCounter   uint
+	missedCounter uint
+	mutex         sync.RWMutex
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+type IDInterface interface {
+	ID() string
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// New returns the pointer to a new ARC cache.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the cache.
+func New[T any](maxSize int) *ARCCache[T] {
+	cache := ARCCache[T]{
+		t1:      list.New(),
+		t2:      list.New(),
+		b1:      list.New(),
+		b2:      list.New(),
+		index:   make(map[string]entry),
+		maxSize: maxSize,
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided ID.
+// If the ID doesn't exist 'false' is returned.
+// A hit moves the entry to the frequently used entries.
+func (p *ARCCache[T]) Get(id string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	e, ok := p.index[id]
+	if !ok || !p.resident(e) {
+		var dummy T
+
+		p.missedCounter++
+
+		return dummy, false
+	}
+
+	p.hitsCounter++
+
+	cacheItem := e.element.Value.(item[T])
+
+	p.move(id, e, p.t2, cacheItem)
+
+	return cacheItem.value, true
+}
+
+// Contains checks if the cache contains an element with
+// the provided ID.
+func (p *ARCCache[T]) Contains(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	e, ok := p.index[id]
+
+	return ok && p.resident(e)
+}
+
+// String implements the Stringer interface.
+// The entries seen once are listed before the entries seen
+// at least twice, each from the least to the most recently used.
+func (p *ARCCache[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	followingItems := false
+
+	for _, l := range []*list.List{p.t1, p.t2} {
+		for element := l.Back(); element != nil; element = element.Prev() {
+			if followingItems {
+				str.WriteString(",")
+			} else {
+				followingItems = true
+			}
+
+			_, _ = fmt.Fprintf(&str, "%v", element.Value.(item[T]).value)
+		}
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// AddByID adds the provided argument with the provided ID to the ARC cache.
+// If the added item is a new one and the ARC cache has already reached its
+// maximum size, an entry is evicted depending on the adaptive target size.
+// If the added item is already part of the ARC cache its value is updated
+// and it is moved to the frequently used entries.
+func (p *ARCCache[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize <= 0 {
+		return &OverflowError{}
+	}
+
+	cacheItem := item[T]{id: id, value: arg}
+
+	if e, ok := p.index[id]; ok {
+		switch e.list {
+		case p.b1:
+			delta := 1
+			if p.b1.Len() > 0 && p.b2.Len()/p.b1.Len() > delta {
+				delta = p.b2.Len() / p.b1.Len()
+			}
+
+			p.target += delta
+			if p.target > p.maxSize {
+				p.target = p.maxSize
+			}
+
+			p.replace(false)
+		case p.b2:
+			delta := 1
+			if p.b2.Len() > 0 && p.b1.Len()/p.b2.Len() > delta {
+				delta = p.b1.Len() / p.b2.Len()
+			}
+
+			p.target -= delta
+			if p.target < 0 {
+				p.target = 0
+			}
+
+			p.replace(true)
+		}
+
+		p.move(id, p.index[id], p.t2, cacheItem)
+
+		return nil
+	}
+
+	if p.t1.Len()+p.b1.Len() >= p.maxSize {
+		if p.t1.Len() < p.maxSize {
+			p.removeLRU(p.b1)
+			p.replace(false)
+		} else {
+			p.removeLRU(p.t1)
+		}
+	} else if total := p.t1.Len() + p.t2.Len() + p.b1.Len() + p.b2.Len(); total >= p.maxSize {
+		if total >= 2*p.maxSize {
+			p.removeLRU(p.b2)
+		}
+
+		p.replace(false)
+	}
+
+	p.index[id] = entry{element: p.t1.PushFront(cacheItem), list: p.t1}
+
+	return nil
+}
+
+// Add adds the provided argument to the ARC cache.
+// The ID used is either provided using the ID interface or generated internally.
+// If the added item is a new one and the ARC cache has already reached its
+// maximum size, an entry is evicted depending on the adaptive target size.
+// If the added item is already part of the ARC cache its value is updated
+// and it is moved to the frequently used entries.
+func (p *ARCCache[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Stats returns the statistics of the provided cache,
+// the hits & the misses.
+func (p *ARCCache[T]) Stats() (uint, uint) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.hitsCounter, p.missedCounter
+}
+
+// Target returns the current adaptive target size for
+// the entries which have been seen only once.
+func (p *ARCCache[T]) Target() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.target
+}
+
+// Length returns the number of cached entries.
+func (p *ARCCache[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.t1.Len() + p.t2.Len()
+}
+
+// GetCache returns the cache content so that it can be
+// used in a 'for range' loop.
+func (p *ARCCache[T]) GetCache() map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for _, l := range []*list.List{p.t1, p.t2} {
+		for element := l.Front(); element != nil; element = element.Next() {
+			cacheItem := element.Value.(item[T])
+			content[cacheItem.id] = cacheItem.value
+		}
+	}
+
+	return content
+}
+
+// resident checks if the entry holds a value or is only a ghost entry.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *ARCCache[T]) resident(e entry) bool {
+	return e.list == p.t1 || e.list == p.t2
+}
+
+// move removes the entry from its current list and inserts the
+// provided item as the most recently used entry of the target list.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ARCCache[T]) move(id string, e entry, target *list.List, cacheItem item[T]) {
+	e.list.Remove(e.element)
+
+	p.index[id] = entry{element: target.PushFront(cacheItem), list: target}
+}
+
+// replace evicts the least recently used entry of T1 or T2 into
+// the corresponding ghost list, depending on the target size.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ARCCache[T]) replace(inB2 bool) {
+	if p.t1.Len() > 0 && (p.t1.Len() > p.target || (inB2 && p.t1.Len() == p.target)) {
+		p.demote(p.t1, p.b1)
+	} else if p.t2.Len() > 0 {
+		p.demote(p.t2, p.b2)
+	} else if p.t1.Len() > 0 {
+		p.demote(p.t1, p.b1)
+	}
+}
+
+// demote moves the least recently used entry of the provided resident
+// list to the provided ghost list and drops its value.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ARCCache[T]) demote(from *list.List, to *list.List) {
+	element := from.Back()
+	cacheItem := element.Value.(item[T])
+
+	p.move(cacheItem.id, p.index[cacheItem.id], to, item[T]{id: cacheItem.id})
+}
+
+// removeLRU removes the least recently used entry of the provided list.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ARCCache[T]) removeLRU(l *list.List) {
+	element := l.Back()
+	if element == nil {
+		return
+	}
+
+	l.Remove(element)
+
+	delete(p.index, element.Value.(item[T]).id)
+}
@@ -0,0 +1,133 @@
+package arccache_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/piccobit/generics/arccache"
+)
+
+type car struct {
+	name       string
+	colour     string
+	horsepower int
+}
+
+func (p *car) ID() string {
+	return p.name
+}
+
+func ExampleARCCache_Add_string() {
+	var err error
+
+	myStringARC := arccache.New[string](10)
+
+	for i := 1; i <= 10; i++ {
+		_, err = myStringARC.Add(strconv.Itoa(i))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	_, err = myStringARC.Add("11")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v", myStringARC)
+	// Output:
+	// Content: [2,3,4,5,6,7,8,9,10,11]
+}
+
+func ExampleARCCache_AddByID_scan() {
+	var err error
+
+	myStringARC := arccache.New[string](4)
+
+	for _, id := range []string{"a", "b"} {
+		err = myStringARC.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		_, _ = myStringARC.Get(id)
+	}
+
+	// A sequential scan does not wipe out the frequently used entries.
+	for i := 1; i <= 6; i++ {
+		id := "x" + strconv.Itoa(i)
+
+		err = myStringARC.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringARC)
+	fmt.Printf("Contains a: %v\n", myStringARC.Contains("a"))
+	fmt.Printf("Contains x1: %v\n", myStringARC.Contains("x1"))
+	// Output:
+	// Content: [x5,x6,a,b]
+	// Contains a: true
+	// Contains x1: false
+}
+
+func ExampleARCCache_AddByID_ghost() {
+	var err error
+
+	myStringARC := arccache.New[string](2)
+
+	for _, id := range []string{"a", "b", "c"} {
+		err = myStringARC.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		if id == "a" {
+			_, _ = myStringARC.Get(id)
+		}
+	}
+
+	fmt.Printf("Content: %v, Target: %d\n", myStringARC, myStringARC.Target())
+
+	// Adding the recently evicted entry again grows the target size
+	// of the entries seen only once.
+	err = myStringARC.AddByID("b", "b")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v, Target: %d\n", myStringARC, myStringARC.Target())
+	// Output:
+	// Content: [c,a], Target: 0
+	// Content: [c,b], Target: 1
+}
+
+func ExampleARCCache_Get_car() {
+	var err error
+
+	myCarARC := arccache.New[*car](10)
+
+	_, err = myCarARC.Add(&car{
+		name:       "VW",
+		colour:     "blue",
+		horsepower: 60,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myCarARC.Get("VW")
+	fmt.Printf("%v: %v\n", *value, ok)
+
+	_, ok = myCarARC.Get("Corvette")
+	fmt.Printf("Corvette: %v\n", ok)
+
+	hits, misses := myCarARC.Stats()
+	fmt.Printf("Hits: %d, Misses: %d\n", hits, misses)
+	// Output:
+	// {VW blue 60}: true
+	// Corvette: false
+	// Hits: 1, Misses: 1
+}
@@ -0,0 +1,367 @@
+/*
+Package blockcache caches the content of immutable files read with random access.
+The files are split into blocks of a fixed size, which are kept in an LRU or LFU cache
+shared by all files, so a single memory budget applies to any number of files.
+Concurrent reads of the same missing block share a single load, and sequential reads
+start loading the following blocks in the background before they are requested.
+*/
+package blockcache
+
+import (
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/piccobit/generics/lfucache"
+	"github.com/piccobit/generics/lrucache"
+)
+
+const (
+	// DefaultBlockSize is the default size of the blocks in bytes.
+	DefaultBlockSize = 64 * 1024
+
+	// DefaultReadahead is the default number of blocks loaded ahead of sequential reads.
+	DefaultReadahead = 4
+)
+
+// blockStore is implemented by the LRU & the LFU cache.
+type blockStore interface {
+	Get(id string) ([]byte, bool)
+	AddByID(id string, arg []byte) error
+	RemovePrefix(prefix string) int
+}
+
+// Stats holds the statistics of a block cache.
+type Stats struct {
+	Hits   uint
+	Misses uint
+	// Loads & LoadErrors count the blocks read from the files,
+	// including the blocks read ahead.
+	Loads      uint
+	LoadErrors uint
+	// Deduplicated counts the reads which waited for a running load
+	// of the same block.
+	Deduplicated uint
+	// Readaheads counts the blocks loaded ahead of sequential reads.
+	Readaheads uint
+}
+
+type OffsetError struct{}
+
+func (e *OffsetError) Error() string {
+	return "Offset error"
+}
+
+// Option configures optional behaviour of a block cache.
+type Option func(*options)
+
+type options struct {
+	blockSize int
+	readahead int
+	lfu       bool
+}
+
+// WithBlockSize sets the size of the blocks in bytes.
+// The default size is 'DefaultBlockSize'.
+func WithBlockSize(blockSize int) Option {
+	return func(o *options) {
+		o.blockSize = blockSize
+	}
+}
+
+// WithReadahead sets the number of blocks loaded ahead of sequential reads,
+// 0 disables the readahead. The default is 'DefaultReadahead'.
+func WithReadahead(blocks int) Option {
+	return func(o *options) {
+		o.readahead = blocks
+	}
+}
+
+// WithLFU keeps the blocks in an LFU cache instead of an LRU cache.
+func WithLFU() Option {
+	return func(o *options) {
+		o.lfu = true
+	}
+}
+
+type call struct {
+	done  chan struct{}
+	block []byte
+	err   error
+}
+
+type Cache struct {
+	blocks blockStore
+	// promote is set for the LRU cache, whose reads don't
+	// update the recency of the blocks.
+	promote   bool
+	blockSize int64
+	readahead int
+	calls     map[string]*call
+	stats     Stats
+	running   sync.WaitGroup
+	mutex     sync.Mutex
+}
+
+// New returns the pointer to a new block cache.
+// The 'maxBytes' parameter allows to specify the memory budget
+// shared by all files read using the cache.
+// The optional 'opts' parameters allow to change the block size,
+// the readahead and the cache type.
+func New(maxBytes int64, opts ...Option) *Cache {
+	o := options{
+		blockSize: DefaultBlockSize,
+		readahead: DefaultReadahead,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.blockSize < 1 {
+		o.blockSize = DefaultBlockSize
+	}
+
+	if o.readahead < 0 {
+		o.readahead = 0
+	}
+
+	maxBlocks := int(maxBytes / int64(o.blockSize))
+	if maxBlocks < 1 {
+		maxBlocks = 1
+	}
+
+	cache := Cache{
+		blockSize: int64(o.blockSize),
+		readahead: o.readahead,
+		calls:     make(map[string]*call),
+	}
+
+	if o.lfu {
+		cache.blocks = lfucache.New[[]byte](maxBlocks, lfucache.WithPrefixIndex())
+	} else {
+		cache.blocks = lrucache.New[[]byte](maxBlocks, lrucache.WithPrefixIndex())
+		cache.promote = true
+	}
+
+	return &cache
+}
+
+// Open returns a file reading the provided reader through the cache.
+// The 'id' parameter identifies the file in the cache, so opening the
+// same file again with the same ID reuses its cached blocks.
+// The content of the reader must not change.
+func (p *Cache) Open(id string, reader io.ReaderAt) *File {
+	file := File{
+		cache:  p,
+		id:     id,
+		reader: reader,
+		last:   -1,
+	}
+
+	return &file
+}
+
+// Forget removes all cached blocks of the file with the provided ID
+// and returns their number.
+func (p *Cache) Forget(id string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.blocks.RemovePrefix(id + "\x00")
+}
+
+// Stats returns the statistics of the cache.
+func (p *Cache) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.stats
+}
+
+// Wait waits until all running readaheads are finished.
+func (p *Cache) Wait() {
+	p.running.Wait()
+}
+
+// BlockSize returns the size of the blocks in bytes.
+func (p *Cache) BlockSize() int {
+	return int(p.blockSize)
+}
+
+type File struct {
+	cache  *Cache
+	id     string
+	reader io.ReaderAt
+	// last is the index of the last block read, end the index of
+	// the last block of the file once it is known.
+	last  int64
+	end   int64
+	known bool
+	mutex sync.Mutex
+}
+
+// ReadAt implements 'io.ReaderAt' reading the blocks through the cache.
+func (p *File) ReadAt(b []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, &OffsetError{}
+	}
+
+	if len(b) == 0 {
+		return 0, nil
+	}
+
+	blockSize := p.cache.blockSize
+	first := off / blockSize
+	n := 0
+
+	for n < len(b) {
+		position := off + int64(n)
+		index := position / blockSize
+
+		block, err := p.load(index, false)
+		if err != nil {
+			return n, err
+		}
+
+		start := int(position - index*blockSize)
+		if start >= len(block) {
+			return n, io.EOF
+		}
+
+		n += copy(b[n:], block[start:])
+
+		if len(block) < int(blockSize) && n < len(b) {
+			return n, io.EOF
+		}
+	}
+
+	p.readahead(first, (off+int64(n)-1)/blockSize)
+
+	return n, nil
+}
+
+// ID returns the ID of the file in the cache.
+func (p *File) ID() string {
+	return p.id
+}
+
+// readahead loads the blocks following the provided range in the background,
+// if it continues the previous read and reaches a new block.
+func (p *File) readahead(first int64, last int64) {
+	p.mutex.Lock()
+
+	sequential := first == p.last || first == p.last+1
+	advanced := last > p.last
+	p.last = last
+
+	end, known := p.end, p.known
+
+	p.mutex.Unlock()
+
+	if !sequential || !advanced {
+		return
+	}
+
+	for index := last + 1; index <= last+int64(p.cache.readahead); index++ {
+		if known && index > end {
+			return
+		}
+
+		p.cache.running.Add(1)
+
+		go func(index int64) {
+			defer p.cache.running.Done()
+
+			_, _ = p.load(index, true)
+		}(index)
+	}
+}
+
+// load returns the block with the provided index, reading it if it
+// isn't cached. Concurrent calls for the same block share a single read.
+func (p *File) load(index int64, ahead bool) ([]byte, error) {
+	c := p.cache
+	id := p.id + "\x00" + strconv.FormatInt(index, 10)
+
+	c.mutex.Lock()
+
+	if block, ok := c.blocks.Get(id); ok {
+		if !ahead {
+			c.stats.Hits++
+
+			if c.promote {
+				_ = c.blocks.AddByID(id, block)
+			}
+		}
+
+		c.mutex.Unlock()
+
+		return block, nil
+	}
+
+	if running, ok := c.calls[id]; ok {
+		if !ahead {
+			c.stats.Deduplicated++
+		}
+
+		c.mutex.Unlock()
+
+		if ahead {
+			return nil, nil
+		}
+
+		<-running.done
+
+		return running.block, running.err
+	}
+
+	if ahead {
+		c.stats.Readaheads++
+	} else {
+		c.stats.Misses++
+	}
+
+	c.stats.Loads++
+
+	running := &call{done: make(chan struct{})}
+	c.calls[id] = running
+
+	c.mutex.Unlock()
+
+	block := make([]byte, c.blockSize)
+
+	n, err := p.reader.ReadAt(block, index*c.blockSize)
+	if err == io.EOF {
+		err = nil
+	}
+
+	running.block, running.err = block[:n], err
+
+	if err == nil && n < len(block) {
+		p.mutex.Lock()
+
+		p.end, p.known = index, true
+		if n == 0 {
+			p.end--
+		}
+
+		p.mutex.Unlock()
+	}
+
+	c.mutex.Lock()
+
+	delete(c.calls, id)
+
+	if err != nil {
+		c.stats.LoadErrors++
+	} else if n > 0 {
+		_ = c.blocks.AddByID(id, running.block)
+	}
+
+	c.mutex.Unlock()
+
+	close(running.done)
+
+	return running.block, running.err
+}
@@ -0,0 +1,167 @@
+package blockcache_test
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piccobit/generics/blockcache"
+)
+
+// countingReader counts the reads returning data.
+type countingReader struct {
+	reader io.ReaderAt
+	delay  time.Duration
+	reads  int32
+}
+
+func (r *countingReader) ReadAt(b []byte, off int64) (int, error) {
+	time.Sleep(r.delay)
+
+	n, err := r.reader.ReadAt(b, off)
+	if n > 0 {
+		atomic.AddInt32(&r.reads, 1)
+	}
+
+	return n, err
+}
+
+func ExampleFile_ReadAt() {
+	reader := &countingReader{reader: strings.NewReader("The quick brown fox jumps over the lazy dog")}
+
+	cache := blockcache.New(1024, blockcache.WithBlockSize(8), blockcache.WithReadahead(0))
+	file := cache.Open("fox.txt", reader)
+
+	for i := 0; i < 2; i++ {
+		b := make([]byte, 9)
+
+		n, err := file.ReadAt(b, 10)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("ReadAt: %q\n", b[:n])
+	}
+
+	b := make([]byte, 10)
+	n, err := file.ReadAt(b, 40)
+	fmt.Printf("ReadAt: %q %v\n", b[:n], err)
+
+	stats := cache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d, Reads: %d\n", stats.Hits, stats.Misses, atomic.LoadInt32(&reader.reads))
+	// Output:
+	// ReadAt: "brown fox"
+	// ReadAt: "brown fox"
+	// ReadAt: "dog" EOF
+	// Hits: 2, Misses: 3, Reads: 3
+}
+
+func ExampleNew_recency() {
+	reader := &countingReader{reader: strings.NewReader("0123456789abcdefghijklmnopqrstuv")}
+
+	// A budget of 3 blocks, the hot block 0 is read between all other blocks.
+	cache := blockcache.New(12, blockcache.WithBlockSize(4), blockcache.WithReadahead(0))
+	file := cache.Open("hot.txt", reader)
+
+	b := make([]byte, 4)
+
+	for _, index := range []int64{0, 5, 0, 6, 0, 7, 0} {
+		_, err := file.ReadAt(b, index*4)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	stats := cache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d\n", stats.Hits, stats.Misses)
+	// Output:
+	// Hits: 3, Misses: 4
+}
+
+func ExampleWithReadahead() {
+	data := bytes.Repeat([]byte("0123456789"), 10)
+	reader := &countingReader{reader: bytes.NewReader(data)}
+
+	cache := blockcache.New(1024, blockcache.WithBlockSize(16), blockcache.WithReadahead(2))
+	file := cache.Open("digits.txt", reader)
+
+	var content []byte
+
+	b := make([]byte, 10)
+
+	for off := int64(0); ; off += 10 {
+		n, err := file.ReadAt(b, off)
+		content = append(content, b[:n]...)
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	cache.Wait()
+
+	stats := cache.Stats()
+	fmt.Printf("Equal: %t\n", bytes.Equal(content, data))
+	fmt.Printf("Reads: %d, Read ahead: %t\n", atomic.LoadInt32(&reader.reads), stats.Readaheads > 0)
+	// Output:
+	// Equal: true
+	// Reads: 7, Read ahead: true
+}
+
+func ExampleCache_Open() {
+	reader := &countingReader{
+		reader: strings.NewReader("The quick brown fox jumps over the lazy dog"),
+		delay:  10 * time.Millisecond,
+	}
+
+	cache := blockcache.New(1024, blockcache.WithBlockSize(8), blockcache.WithReadahead(0))
+	file := cache.Open("fox.txt", reader)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			b := make([]byte, 5)
+			_, _ = file.ReadAt(b, 16)
+		}()
+	}
+
+	wg.Wait()
+
+	stats := cache.Stats()
+	fmt.Printf("Reads: %d, Requests: %d\n", atomic.LoadInt32(&reader.reads), stats.Hits+stats.Misses+stats.Deduplicated)
+	// Output:
+	// Reads: 1, Requests: 10
+}
+
+func ExampleCache_Forget() {
+	data := bytes.Repeat([]byte("x"), 64)
+
+	// Both files share a budget of four blocks.
+	cache := blockcache.New(64, blockcache.WithBlockSize(16), blockcache.WithReadahead(0))
+	first := cache.Open("first", bytes.NewReader(data))
+	second := cache.Open("second", bytes.NewReader(data))
+
+	b := make([]byte, 64)
+	_, _ = first.ReadAt(b, 0)
+	_, _ = second.ReadAt(b, 0)
+
+	fmt.Printf("Forget first: %d\n", cache.Forget("first"))
+	fmt.Printf("Forget second: %d\n", cache.Forget("second"))
+	// Output:
+	// Forget first: 0
+	// Forget second: 4
+}
@@ -5,12 +5,17 @@ package cache
 
 import (
 	"sync"
+
+	"github.com/piccobit/generics/event"
+	"github.com/piccobit/generics/radix"
 )
 
 type Cache[T any] struct {
-	content map[string]T
-	maxSize int
-	mutex   sync.RWMutex
+	content     map[string]T
+	maxSize     int
+	prefixIndex *radix.Tree
+	events      event.Broker[T]
+	mutex       sync.RWMutex
 }
 
 type UnderflowError struct{}
@@ -24,16 +29,43 @@ func (e *OverflowError) Error() string {
 	return "Overflow error"
 }
 
+// Option configures optional behaviour of a cache.
+type Option func(*options)
+
+type options struct {
+	prefixIndex bool
+}
+
+// WithPrefixIndex maintains a radix tree of the keys,
+// so that the prefix operations don't scan all entries.
+func WithPrefixIndex() Option {
+	return func(o *options) {
+		o.prefixIndex = true
+	}
+}
+
 // New returns the pointer to a new cache.
 // The 'maxSize' parameter allows to specify a
 // maximum size for the cache. Setting this to 0
 // allows the cache to grow infinitely.
-func New[T any](maxSize int) *Cache[T] {
+// The optional 'opts' parameters allow to enable
+// the prefix index.
+func New[T any](maxSize int, opts ...Option) *Cache[T] {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	cache := Cache[T]{
 		content: map[string]T{},
 		maxSize: maxSize,
 	}
 
+	if o.prefixIndex {
+		cache.prefixIndex = radix.New()
+	}
+
 	return &cache
 }
 
@@ -55,16 +87,23 @@ func (p *Cache[T]) Load(key string) (T, bool) {
 
 // Save stores the given value indexed by the also provided key,
 // If the maximum size of the cache is reached an Overflow error
-// is returned.
+// is returned for new keys, existing keys can still be updated.
 func (p *Cache[T]) Save(key string, value T) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if p.maxSize > 0 && len(p.content) >= p.maxSize {
+	kind := event.Added
+
+	if _, ok := p.content[key]; ok {
+		kind = event.Updated
+	} else if p.maxSize > 0 && len(p.content) >= p.maxSize {
 		return &OverflowError{}
+	} else if p.prefixIndex != nil {
+		p.prefixIndex.Insert(key)
 	}
 
 	p.content[key] = value
+	p.publish(kind, key, value)
 
 	return nil
 }
@@ -1,7 +1,9 @@
 package cache_test
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/piccobit/generics/cache"
 )
@@ -53,3 +55,66 @@ func ExampleCache_Save() {
 	// 42: true
 	// 0: false
 }
+
+func ExampleCache_Save_full() {
+	myCache := cache.New[int](1)
+
+	_ = myCache.Save("foo", 13)
+
+	// Existing keys can still be updated, new keys overflow.
+	err := myCache.Save("foo", 42)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myCache.Save("bar", 42)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myCache.Load("foo")
+	fmt.Printf("%d: %v\n", value, ok)
+	// Output:
+	// ERROR: Overflow error
+	// 42: true
+}
+
+func ExampleCache_Subscribe() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	myCache := cache.New[int](0)
+
+	events := myCache.Subscribe(ctx)
+
+	_ = myCache.Save("foo", 13)
+	_ = myCache.Save("foo", 42)
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s %s: %d\n", e.Kind, e.ID, e.Value)
+	}
+	// Output:
+	// Added foo: 13
+	// Updated foo: 42
+}
+
+func ExampleCache_RemovePrefix() {
+	myCache := cache.New[int](0, cache.WithPrefixIndex())
+
+	_ = myCache.Save("tenant:42:user:7", 7)
+	_ = myCache.Save("tenant:42:user:8", 8)
+	_ = myCache.Save("tenant:43:user:1", 1)
+
+	fmt.Printf("Tenant 42: %v\n", myCache.FilterPrefix("tenant:42:"))
+
+	fmt.Printf("Removed: %d\n", myCache.RemovePrefix("tenant:42:"))
+
+	fmt.Printf("Remaining: %v\n", myCache.Filter(func(key string, value int) bool {
+		return true
+	}))
+	// Output:
+	// Tenant 42: map[tenant:42:user:7:7 tenant:42:user:8:8]
+	// Removed: 2
+	// Remaining: map[tenant:43:user:1:1]
+}
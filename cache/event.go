@@ -0,0 +1,19 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/piccobit/generics/event"
+)
+
+// Subscribe reports the saved and removed entries of the cache until the
+// context is cancelled. See 'event.Broker.Subscribe' for the delivery
+// of the events and the options.
+func (p *Cache[T]) Subscribe(ctx context.Context, opts ...event.Option) <-chan event.Event[T] {
+	return p.events.Subscribe(ctx, opts...)
+}
+
+// publish reports the modification of the entry with the provided ID to the subscribers.
+func (p *Cache[T]) publish(kind event.Kind, id string, value T) {
+	p.events.Publish(event.Event[T]{Kind: kind, ID: id, Value: value})
+}
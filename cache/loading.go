@@ -0,0 +1,13 @@
+package cache
+
+import (
+	"github.com/piccobit/generics/loading"
+)
+
+// NewLoading returns the pointer to a new loading cache, which stores its
+// entries in a new cache with the provided maximum size.
+// Missing values are loaded using the provided loader, the optional 'opts'
+// parameters allow to set the TTLs and to configure the background refreshes.
+func NewLoading[T any](maxSize int, loader loading.Loader[T], opts ...loading.Option) *loading.Cache[T] {
+	return loading.New[T](New[loading.Entry[T]](maxSize), loader, opts...)
+}
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"strings"
+
+	"github.com/piccobit/generics/event"
+)
+
+// RemoveFunc removes all entries for which the provided function returns
+// 'true' and returns the number of removed entries.
+// The function must not use the cache.
+func (p *Cache[T]) RemoveFunc(fn func(key string, value T) bool) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var removed []string
+
+	for key, value := range p.content {
+		if fn(key, value) {
+			removed = append(removed, key)
+		}
+	}
+
+	for _, key := range removed {
+		p.remove(key)
+	}
+
+	return len(removed)
+}
+
+// RemovePrefix removes all entries whose key starts with the provided
+// prefix and returns the number of removed entries.
+func (p *Cache[T]) RemovePrefix(prefix string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var removed []string
+
+	p.walkPrefix(prefix, func(key string) {
+		removed = append(removed, key)
+	})
+
+	for _, key := range removed {
+		p.remove(key)
+	}
+
+	return len(removed)
+}
+
+// Filter returns the entries for which the provided function returns 'true'.
+// The function must not use the cache.
+func (p *Cache[T]) Filter(fn func(key string, value T) bool) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for key, value := range p.content {
+		if fn(key, value) {
+			content[key] = value
+		}
+	}
+
+	return content
+}
+
+// FilterPrefix returns the entries whose key starts with the provided prefix.
+func (p *Cache[T]) FilterPrefix(prefix string) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	p.walkPrefix(prefix, func(key string) {
+		content[key] = p.content[key]
+	})
+
+	return content
+}
+
+// remove removes the entry with the provided key.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[T]) remove(key string) {
+	value := p.content[key]
+
+	delete(p.content, key)
+
+	if p.prefixIndex != nil {
+		p.prefixIndex.Delete(key)
+	}
+
+	p.publish(event.Removed, key, value)
+}
+
+// walkPrefix calls the provided function for all keys with the provided prefix,
+// using the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Cache[T]) walkPrefix(prefix string, fn func(key string)) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.WalkPrefix(prefix, func(key string) bool {
+			fn(key)
+
+			return true
+		})
+
+		return
+	}
+
+	for key := range p.content {
+		if strings.HasPrefix(key, prefix) {
+			fn(key)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+/*
+Package cachesim replays traces of cache accesses against the caches of this module,
+so that the cache policies can be compared using real access patterns.
+For every access missing the cache the accessed entry is added to the cache afterwards.
+*/
+package cachesim
+
+import (
+	"sort"
+
+	"github.com/piccobit/generics/arccache"
+	"github.com/piccobit/generics/cache"
+	"github.com/piccobit/generics/clockcache"
+	"github.com/piccobit/generics/lfucache"
+	"github.com/piccobit/generics/lrucache"
+	"github.com/piccobit/generics/slru"
+	"github.com/piccobit/generics/tinylfu"
+	"github.com/piccobit/generics/twoqueue"
+)
+
+// Policy is a cache simulated by the replay of a trace.
+type Policy interface {
+	// Get reports if the entry with the provided key is cached.
+	Get(key string) bool
+	// Set adds the entry with the provided key & size to the cache.
+	Set(key string, size int64) error
+	// Length returns the number of cached entries.
+	Length() int
+}
+
+// Factory returns a new policy with the provided capacity,
+// given as the maximum number of cached entries.
+type Factory func(capacity int) Policy
+
+// Result holds the statistics of a single replay of a trace.
+type Result struct {
+	Policy    string
+	Capacity  int
+	Requests  uint64
+	Hits      uint64
+	Bytes     int64
+	HitBytes  int64
+	Evictions uint64
+	Rejected  uint64
+}
+
+// HitRatio returns the share of the requests which hit the cache.
+func (r Result) HitRatio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+
+	return float64(r.Hits) / float64(r.Requests)
+}
+
+// ByteHitRatio returns the share of the requested bytes which hit the cache.
+func (r Result) ByteHitRatio() float64 {
+	if r.Bytes == 0 {
+		return 0
+	}
+
+	return float64(r.HitBytes) / float64(r.Bytes)
+}
+
+type idCache interface {
+	Get(id string) (int64, bool)
+	AddByID(id string, arg int64) error
+	GetCache() map[string]int64
+}
+
+// idPolicy adapts the caches using the 'Get' & 'AddByID' methods.
+type idPolicy struct {
+	cache idCache
+}
+
+func (p *idPolicy) Get(key string) bool {
+	_, ok := p.cache.Get(key)
+
+	return ok
+}
+
+func (p *idPolicy) Set(key string, size int64) error {
+	return p.cache.AddByID(key, size)
+}
+
+func (p *idPolicy) Length() int {
+	return len(p.cache.GetCache())
+}
+
+// savePolicy adapts the simple cache, which never evicts entries
+// but rejects new ones if it is full.
+type savePolicy struct {
+	cache  *cache.Cache[int64]
+	length int
+}
+
+func (p *savePolicy) Get(key string) bool {
+	_, ok := p.cache.Load(key)
+
+	return ok
+}
+
+func (p *savePolicy) Set(key string, size int64) error {
+	if err := p.cache.Save(key, size); err != nil {
+		return err
+	}
+
+	p.length++
+
+	return nil
+}
+
+func (p *savePolicy) Length() int {
+	return p.length
+}
+
+// Policies returns the factories of all caches of this module by name.
+// Further policies can be added to the returned map before passing it
+// to 'Simulate'.
+func Policies() map[string]Factory {
+	return map[string]Factory{
+		"cache": func(capacity int) Policy {
+			return &savePolicy{cache: cache.New[int64](capacity)}
+		},
+		"lrucache": func(capacity int) Policy {
+			return &idPolicy{cache: lrucache.New[int64](capacity)}
+		},
+		"lfucache": func(capacity int) Policy {
+			return &idPolicy{cache: lfucache.New[int64](capacity)}
+		},
+		"arccache": func(capacity int) Policy {
+			return &idPolicy{cache: arccache.New[int64](capacity)}
+		},
+		"tinylfu": func(capacity int) Policy {
+			return &idPolicy{cache: tinylfu.New[int64](capacity)}
+		},
+		"twoqueue": func(capacity int) Policy {
+			return &idPolicy{cache: twoqueue.New[int64](capacity)}
+		},
+		"slru": func(capacity int) Policy {
+			return &idPolicy{cache: slru.New[int64](capacity)}
+		},
+		"clockcache": func(capacity int) Policy {
+			return &idPolicy{cache: clockcache.New[int64](capacity)}
+		},
+		"clockpro": func(capacity int) Policy {
+			return &idPolicy{cache: clockcache.New[int64](capacity, clockcache.WithClockPro())}
+		},
+	}
+}
+
+// Replay replays the trace against the provided policy. Entries which the
+// policy refuses to add are counted as rejected. As entries are never removed
+// explicitly, every added entry which isn't cached at the end was evicted.
+func Replay(trace []Request, policy Policy) Result {
+	var result Result
+
+	var added uint64
+
+	for _, request := range trace {
+		result.Requests++
+		result.Bytes += request.Size
+
+		if policy.Get(request.Key) {
+			result.Hits++
+			result.HitBytes += request.Size
+
+			continue
+		}
+
+		if err := policy.Set(request.Key, request.Size); err != nil {
+			result.Rejected++
+
+			continue
+		}
+
+		added++
+	}
+
+	result.Evictions = added - uint64(policy.Length())
+
+	return result
+}
+
+// Simulate replays the trace against every provided policy with every
+// provided capacity. The results are sorted by policy name & capacity.
+func Simulate(trace []Request, policies map[string]Factory, capacities []int) []Result {
+	names := make([]string, 0, len(policies))
+
+	for name := range policies {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	sortedCapacities := append([]int(nil), capacities...)
+	sort.Ints(sortedCapacities)
+
+	results := make([]Result, 0, len(names)*len(sortedCapacities))
+
+	for _, name := range names {
+		for _, capacity := range sortedCapacities {
+			result := Replay(trace, policies[name](capacity))
+			result.Policy = name
+			result.Capacity = capacity
+
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
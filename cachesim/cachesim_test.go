@@ -0,0 +1,67 @@
+package cachesim_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/piccobit/generics/cachesim"
+)
+
+func ExampleReadTrace() {
+	trace, err := cachesim.ReadTrace(strings.NewReader("key,size,timestamp\na,100,1700000000\nb,2000,2023-11-14T22:13:21Z\n"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for _, request := range trace {
+		fmt.Printf("%s %d %s\n", request.Key, request.Size, request.Time.Format("2006-01-02T15:04:05Z"))
+	}
+
+	_, err = cachesim.ReadTrace(strings.NewReader("a\nb,x\n"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// a 100 2023-11-14T22:13:20Z
+	// b 2000 2023-11-14T22:13:21Z
+	// ERROR: Format error in line 2
+}
+
+func ExampleReadTrace_malformed() {
+	for _, input := range []string{"\"abc", "a\n\"b\"x", "a,1,notatime\nb\n"} {
+		_, err := cachesim.ReadTrace(strings.NewReader(input))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+		}
+	}
+	// Output:
+	// ERROR: Format error in line 1
+	// ERROR: Format error in line 2
+	// ERROR: Format error in line 1
+}
+
+func ExampleSimulate() {
+	trace, err := cachesim.ReadTrace(strings.NewReader("a\nb\nc\na\nb\nd\na\nb\nc\n"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	policies := cachesim.Policies()
+
+	results := cachesim.Simulate(trace, map[string]cachesim.Factory{
+		"cache":    policies["cache"],
+		"lrucache": policies["lrucache"],
+	}, []int{3, 2})
+
+	err = cachesim.WriteCSV(os.Stdout, results)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// policy,capacity,requests,hits,hit ratio,byte hit ratio,evictions,rejected
+	// cache,2,9,4,0.4444,0.4444,0,3
+	// cache,3,9,5,0.5556,0.5556,0,1
+	// lrucache,2,9,0,0.0000,0.0000,7,0
+	// lrucache,3,9,2,0.2222,0.2222,4,0
+}
@@ -0,0 +1,65 @@
+package cachesim
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+var reportHeader = []string{"policy", "capacity", "requests", "hits", "hit ratio", "byte hit ratio", "evictions", "rejected"}
+
+// WriteText writes the results as aligned text table.
+func WriteText(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	for i, column := range reportHeader {
+		if i > 0 {
+			_, _ = fmt.Fprint(tw, "\t")
+		}
+
+		_, _ = fmt.Fprint(tw, column)
+	}
+
+	_, _ = fmt.Fprint(tw, "\n")
+
+	for _, result := range results {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.4f\t%.4f\t%d\t%d\n",
+			result.Policy,
+			result.Capacity,
+			result.Requests,
+			result.Hits,
+			result.HitRatio(),
+			result.ByteHitRatio(),
+			result.Evictions,
+			result.Rejected,
+		)
+	}
+
+	return tw.Flush()
+}
+
+// WriteCSV writes the results as CSV including a header line.
+func WriteCSV(w io.Writer, results []Result) error {
+	writer := csv.NewWriter(w)
+
+	_ = writer.Write(reportHeader)
+
+	for _, result := range results {
+		_ = writer.Write([]string{
+			result.Policy,
+			strconv.Itoa(result.Capacity),
+			strconv.FormatUint(result.Requests, 10),
+			strconv.FormatUint(result.Hits, 10),
+			strconv.FormatFloat(result.HitRatio(), 'f', 4, 64),
+			strconv.FormatFloat(result.ByteHitRatio(), 'f', 4, 64),
+			strconv.FormatUint(result.Evictions, 10),
+			strconv.FormatUint(result.Rejected, 10),
+		})
+	}
+
+	writer.Flush()
+
+	return writer.Error()
+}
@@ -0,0 +1,128 @@
+package cachesim
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Request is a single access of a trace.
+type Request struct {
+	Key  string
+	Size int64
+	Time time.Time
+}
+
+type FormatError struct {
+	Line int
+}
+
+func (e *FormatError) Error() string {
+	return fmt.Sprintf("Format error in line %d", e.Line)
+}
+
+// ReadTrace reads a trace from the provided reader.
+// Every line of the trace either contains only the key of the accessed
+// entry or the key, the size in bytes and the timestamp separated by commas.
+// The size defaults to 1, the timestamp is either given in seconds since the
+// Unix epoch or in the RFC 3339 format and may be omitted.
+// A first line whose size column isn't a number is treated as a header.
+func ReadTrace(r io.Reader) ([]Request, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+
+	var trace []Request
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return trace, nil
+		}
+
+		if err != nil {
+			line := 0
+
+			var parseError *csv.ParseError
+			if errors.As(err, &parseError) {
+				line = parseError.Line
+			}
+
+			return nil, &FormatError{Line: line}
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		if line == 1 && isHeader(record) {
+			continue
+		}
+
+		request, err := parseRequest(record)
+		if err != nil {
+			return nil, &FormatError{Line: line}
+		}
+
+		trace = append(trace, request)
+	}
+}
+
+// isHeader checks if the provided record is a header,
+// i.e. its size column isn't a number.
+func isHeader(record []string) bool {
+	if len(record) < 2 {
+		return false
+	}
+
+	_, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+
+	return err != nil
+}
+
+// parseRequest converts a single record of a trace.
+func parseRequest(record []string) (Request, error) {
+	request := Request{
+		Key:  strings.TrimSpace(record[0]),
+		Size: 1,
+	}
+
+	if request.Key == "" || len(record) > 3 {
+		return request, &FormatError{}
+	}
+
+	if len(record) > 1 {
+		size, err := strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+		if err != nil || size < 0 {
+			return request, &FormatError{}
+		}
+
+		request.Size = size
+	}
+
+	if len(record) > 2 && strings.TrimSpace(record[2]) != "" {
+		timestamp, err := parseTime(strings.TrimSpace(record[2]))
+		if err != nil {
+			return request, err
+		}
+
+		request.Time = timestamp
+	}
+
+	return request, nil
+}
+
+// parseTime converts a timestamp given in seconds since the
+// Unix epoch or in the RFC 3339 format.
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+		whole := int64(seconds)
+
+		return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).UTC(), nil
+	}
+
+	return time.Parse(time.RFC3339Nano, value)
+}
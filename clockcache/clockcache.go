@@ -0,0 +1,507 @@
+/*
+Package clockcache is a simple generic implementation of a CLOCK cache, an approximation of an LRU cache.
+The cached entries are arranged in a circular list. Every entry has a reference bit, which is set on
+each access without taking a lock, so reads do not contend with each other. To make place for a new
+entry a hand sweeps over the list, clears the reference bits it passes and evicts the first entry
+which has not been referenced since the hand passed it the last time.
+
+Optionally the cache runs in CLOCK-Pro mode, which distinguishes hot and cold entries and remembers
+recently evicted cold entries, so a single sequential scan does not wipe out the frequently used entries.
+*/
+package clockcache
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+type status int
+
+const (
+	cold status = iota
+	hot
+	test
+)
+
+type entry[T any] struct {
+	referenced uint32
+	id         string
+	value      T
+	status     status
+}
+
+type ClockCache[T any] struct {
+	hitsCounter   uint64
+	missedCounter uint64
+	resident      sync.Map
+	clock         *list.List
+	index         map[string]*list.Element
+	hand          *list.Element
+	handHot       *list.Element
+	handCold      *list.Element
+	handTest      *list.Element
+	maxSize       int
+	coldTarget    int
+	countHot      int
+	countCold     int
+	countTest     int
+	clockPro      bool
+	mutex         sync.RWMutex
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+type IDInterface interface {
+	ID() string
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// Option configures optional behaviour of a CLOCK cache.
+type Option func(*options)
+
+type options struct {
+	clockPro bool
+}
+
+// WithClockPro enables the scan resistant CLOCK-Pro mode.
+// A cache with a maximum size below 2 has no room for both a hot
+// and a cold entry, so it uses the plain CLOCK mode instead.
+func WithClockPro() Option {
+	return func(o *options) {
+		o.clockPro = true
+	}
+}
+
+// New returns the pointer to a new CLOCK cache.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the cache.
+// The optional 'opts' parameters allow to enable
+// the CLOCK-Pro mode.
+func New[T any](maxSize int, opts ...Option) *ClockCache[T] {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cache := ClockCache[T]{
+		clock:      list.New(),
+		index:      make(map[string]*list.Element),
+		maxSize:    maxSize,
+		coldTarget: 1,
+		clockPro:   o.clockPro && maxSize >= 2,
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided ID.
+// If the ID doesn't exist 'false' is returned.
+// Get doesn't lock the cache, it only sets the
+// reference bit of the entry.
+func (p *ClockCache[T]) Get(id string) (T, bool) {
+	value, ok := p.resident.Load(id)
+	if !ok {
+		var dummy T
+
+		atomic.AddUint64(&p.missedCounter, 1)
+
+		return dummy, false
+	}
+
+	atomic.AddUint64(&p.hitsCounter, 1)
+
+	cacheEntry := value.(*entry[T])
+
+	if atomic.LoadUint32(&cacheEntry.referenced) == 0 {
+		atomic.StoreUint32(&cacheEntry.referenced, 1)
+	}
+
+	return cacheEntry.value, true
+}
+
+// Contains checks if the cache contains an element with
+// the provided ID.
+func (p *ClockCache[T]) Contains(id string) bool {
+	_, ok := p.resident.Load(id)
+
+	return ok
+}
+
+// String implements the Stringer interface.
+// The entries are listed in the order of the clock,
+// starting at the entry the hand points to.
+func (p *ClockCache[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	start := p.hand
+	if p.clockPro {
+		start = p.handHot
+	}
+
+	followingItems := false
+
+	for element, i := start, 0; i < p.clock.Len(); element, i = p.next(element), i+1 {
+		cacheEntry := element.Value.(*entry[T])
+		if cacheEntry.status == test {
+			continue
+		}
+
+		if followingItems {
+			str.WriteString(",")
+		} else {
+			followingItems = true
+		}
+
+		_, _ = fmt.Fprintf(&str, "%v", cacheEntry.value)
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// AddByID adds the provided argument with the provided ID to the cache.
+// New items are added behind the hand, so they are the last ones it visits.
+// If the cache has already reached its maximum size, the hand evicts the
+// first entry which was not referenced since its last pass.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *ClockCache[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize <= 0 {
+		return &OverflowError{}
+	}
+
+	if element, ok := p.index[id]; ok {
+		cacheEntry := element.Value.(*entry[T])
+
+		if cacheEntry.status != test {
+			cacheEntry = &entry[T]{referenced: 1, id: id, value: arg, status: cacheEntry.status}
+			element.Value = cacheEntry
+
+			p.resident.Store(id, cacheEntry)
+
+			return nil
+		}
+
+		// The entry was evicted recently, so the cold entries should
+		// be kept in the cache for a longer time.
+		if p.coldTarget < p.maxSize {
+			p.coldTarget++
+		}
+
+		p.remove(element)
+		p.countTest--
+
+		p.insert(&entry[T]{id: id, value: arg, status: hot})
+
+		return nil
+	}
+
+	p.insert(&entry[T]{id: id, value: arg, status: cold})
+
+	return nil
+}
+
+// Add adds the provided argument to the cache.
+// The ID used is either provided using the ID interface or generated internally.
+// New items are added behind the hand, so they are the last ones it visits.
+// If the cache has already reached its maximum size, the hand evicts the
+// first entry which was not referenced since its last pass.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *ClockCache[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Stats returns the statistics of the provided cache,
+// the hits & the misses.
+func (p *ClockCache[T]) Stats() (uint, uint) {
+	return uint(atomic.LoadUint64(&p.hitsCounter)), uint(atomic.LoadUint64(&p.missedCounter))
+}
+
+// Length returns the number of cached entries.
+func (p *ClockCache[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.countHot + p.countCold
+}
+
+// GetCache returns the cache content so that it can be
+// used in a 'for range' loop.
+func (p *ClockCache[T]) GetCache() map[string]T {
+	content := make(map[string]T)
+
+	p.resident.Range(func(key, value any) bool {
+		content[key.(string)] = value.(*entry[T]).value
+
+		return true
+	})
+
+	return content
+}
+
+// next returns the element following the provided one on the clock.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *ClockCache[T]) next(element *list.Element) *list.Element {
+	if next := element.Next(); next != nil {
+		return next
+	}
+
+	return p.clock.Front()
+}
+
+// prev returns the element preceding the provided one on the clock.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *ClockCache[T]) prev(element *list.Element) *list.Element {
+	if prev := element.Prev(); prev != nil {
+		return prev
+	}
+
+	return p.clock.Back()
+}
+
+// insert makes place for the provided entry and adds it behind the hand.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) insert(cacheEntry *entry[T]) {
+	if p.clockPro {
+		p.runHands()
+	} else {
+		for p.countCold >= p.maxSize {
+			p.runHand()
+		}
+	}
+
+	if cacheEntry.status == hot {
+		p.countHot++
+	} else {
+		p.countCold++
+	}
+
+	var element *list.Element
+
+	switch {
+	case p.clock.Len() == 0:
+		element = p.clock.PushBack(cacheEntry)
+		p.hand, p.handHot, p.handCold, p.handTest = element, element, element, element
+	case p.clockPro:
+		element = p.clock.InsertBefore(cacheEntry, p.handHot)
+
+		if p.handCold == p.handHot {
+			p.handCold = element
+		}
+	default:
+		element = p.clock.InsertBefore(cacheEntry, p.hand)
+	}
+
+	p.index[cacheEntry.id] = element
+	p.resident.Store(cacheEntry.id, cacheEntry)
+}
+
+// remove deletes the provided element from the clock. The CLOCK hand
+// moves on to the following element, the CLOCK-Pro hands move back to
+// the preceding one.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) remove(element *list.Element) {
+	cacheEntry := element.Value.(*entry[T])
+
+	delete(p.index, cacheEntry.id)
+
+	if cacheEntry.status != test {
+		p.resident.Delete(cacheEntry.id)
+	}
+
+	if p.clock.Len() == 1 {
+		p.clock.Remove(element)
+		p.hand, p.handHot, p.handCold, p.handTest = nil, nil, nil, nil
+
+		return
+	}
+
+	if element == p.hand {
+		p.hand = p.next(element)
+	}
+
+	prev := p.prev(element)
+
+	if element == p.handHot {
+		p.handHot = prev
+	}
+
+	if element == p.handCold {
+		p.handCold = prev
+	}
+
+	if element == p.handTest {
+		p.handTest = prev
+	}
+
+	p.clock.Remove(element)
+}
+
+// runHand advances the CLOCK hand by one step. A referenced entry
+// loses its reference bit, an unreferenced entry is evicted.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) runHand() {
+	cacheEntry := p.hand.Value.(*entry[T])
+
+	if atomic.LoadUint32(&cacheEntry.referenced) == 1 {
+		atomic.StoreUint32(&cacheEntry.referenced, 0)
+
+		p.hand = p.next(p.hand)
+
+		return
+	}
+
+	p.countCold--
+
+	p.remove(p.hand)
+}
+
+// runHands runs the CLOCK-Pro hands until there is room for a new resident
+// entry. Every step of the cold hand is followed by the steps of the test and
+// the hot hand needed to keep their targets. All loops are bounded by the
+// length of the clock, if the hands still didn't make room, the next resident
+// entry of the cold hand is evicted.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) runHands() {
+	for steps := 4 * (p.clock.Len() + 1); p.countHot+p.countCold >= p.maxSize && steps > 0; steps-- {
+		p.runHandCold()
+
+		for i := p.clock.Len(); p.countTest > p.maxSize && i > 0; i-- {
+			p.runHandTest()
+		}
+
+		for i := 2*p.clock.Len() + 1; p.countHot > p.maxSize-p.coldTarget && i > 0; i-- {
+			p.runHandHot()
+		}
+	}
+
+	if p.countHot+p.countCold < p.maxSize {
+		return
+	}
+
+	for element := p.handCold; ; element = p.next(element) {
+		if cacheEntry := element.Value.(*entry[T]); cacheEntry.status != test {
+			if cacheEntry.status == hot {
+				p.countHot--
+			} else {
+				p.countCold--
+			}
+
+			p.remove(element)
+
+			return
+		}
+	}
+}
+
+// runHandCold advances the cold hand of CLOCK-Pro by one step.
+// A referenced cold entry becomes hot, an unreferenced cold entry
+// is evicted and only its ID is remembered as test entry.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) runHandCold() {
+	cacheEntry := p.handCold.Value.(*entry[T])
+
+	if cacheEntry.status == cold {
+		if atomic.LoadUint32(&cacheEntry.referenced) == 1 {
+			atomic.StoreUint32(&cacheEntry.referenced, 0)
+
+			cacheEntry.status = hot
+			p.countCold--
+			p.countHot++
+		} else {
+			p.resident.Delete(cacheEntry.id)
+			p.handCold.Value = &entry[T]{id: cacheEntry.id, status: test}
+			p.countCold--
+			p.countTest++
+		}
+	}
+
+	p.handCold = p.next(p.handCold)
+}
+
+// runHandHot advances the hot hand of CLOCK-Pro by one step.
+// A referenced hot entry loses its reference bit, an unreferenced
+// hot entry becomes cold. The test hand is pushed ahead of the hot hand.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) runHandHot() {
+	if p.handHot == p.handTest {
+		p.runHandTest()
+	}
+
+	cacheEntry := p.handHot.Value.(*entry[T])
+
+	if cacheEntry.status == hot {
+		if atomic.LoadUint32(&cacheEntry.referenced) == 1 {
+			atomic.StoreUint32(&cacheEntry.referenced, 0)
+		} else {
+			cacheEntry.status = cold
+			p.countHot--
+			p.countCold++
+		}
+	}
+
+	p.handHot = p.next(p.handHot)
+}
+
+// runHandTest advances the test hand of CLOCK-Pro by one step.
+// A test entry is forgotten, which shortens the time cold
+// entries are kept in the cache. The cold hand is pushed ahead
+// of the test hand.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *ClockCache[T]) runHandTest() {
+	if p.handTest == p.handCold {
+		p.runHandCold()
+	}
+
+	if p.handTest.Value.(*entry[T]).status == test {
+		p.remove(p.handTest)
+		p.countTest--
+
+		if p.coldTarget > 1 {
+			p.coldTarget--
+		}
+	}
+
+	p.handTest = p.next(p.handTest)
+}
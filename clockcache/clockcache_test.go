@@ -0,0 +1,217 @@
+package clockcache_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/piccobit/generics/clockcache"
+	"github.com/piccobit/generics/lrucache"
+)
+
+type car struct {
+	name       string
+	colour     string
+	horsepower int
+}
+
+func (p *car) ID() string {
+	return p.name
+}
+
+func ExampleClockCache_AddByID() {
+	var err error
+
+	myStringCache := clockcache.New[string](3)
+
+	for _, id := range []string{"a", "b", "c"} {
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	// The reference bit gives "a" a second chance.
+	_, _ = myStringCache.Get("a")
+
+	err = myStringCache.AddByID("d", "d")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+	fmt.Printf("Contains b: %v\n", myStringCache.Contains("b"))
+	// Output:
+	// Content: [c,a,d]
+	// Contains b: false
+}
+
+func ExampleWithClockPro() {
+	var err error
+
+	myStringCache := clockcache.New[string](4, clockcache.WithClockPro())
+
+	for i := 0; i < 3; i++ {
+		for _, id := range []string{"a", "b"} {
+			if _, ok := myStringCache.Get(id); ok {
+				continue
+			}
+
+			err = myStringCache.AddByID(id, id)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+			}
+		}
+	}
+
+	// A sequential scan doesn't push out the frequently used entries.
+	for i := 1; i <= 10; i++ {
+		id := "x" + strconv.Itoa(i)
+
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Contains a: %v, b: %v\n", myStringCache.Contains("a"), myStringCache.Contains("b"))
+	fmt.Printf("Length: %d\n", myStringCache.Length())
+	// Output:
+	// Contains a: true, b: true
+	// Length: 4
+}
+
+func ExampleWithClockPro_single() {
+	var err error
+
+	myStringCache := clockcache.New[string](1, clockcache.WithClockPro())
+
+	for _, id := range []string{"0", "1", "0", "1", "0", "1"} {
+		if _, ok := myStringCache.Get(id); ok {
+			continue
+		}
+
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Contains 1: %v\n", myStringCache.Contains("1"))
+	fmt.Printf("Length: %d\n", myStringCache.Length())
+	// Output:
+	// Contains 1: true
+	// Length: 1
+}
+
+func ExampleClockCache_Get_car() {
+	var err error
+
+	myCarCache := clockcache.New[*car](10)
+
+	_, err = myCarCache.Add(&car{
+		name:       "VW",
+		colour:     "blue",
+		horsepower: 60,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myCarCache.Get("VW")
+	fmt.Printf("%v: %v\n", *value, ok)
+
+	_, ok = myCarCache.Get("Corvette")
+	fmt.Printf("Corvette: %v\n", ok)
+
+	hits, misses := myCarCache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d\n", hits, misses)
+	// Output:
+	// {VW blue 60}: true
+	// Corvette: false
+	// Hits: 1, Misses: 1
+}
+
+type benchCache interface {
+	Get(id string) (string, bool)
+	AddByID(id string, arg string) error
+}
+
+func newBenchCaches() map[string]func(maxSize int) benchCache {
+	return map[string]func(maxSize int) benchCache{
+		"clockcache":     func(maxSize int) benchCache { return clockcache.New[string](maxSize) },
+		"clockcache-pro": func(maxSize int) benchCache { return clockcache.New[string](maxSize, clockcache.WithClockPro()) },
+		"lrucache":       func(maxSize int) benchCache { return lrucache.New[string](maxSize) },
+	}
+}
+
+// zipfTrace returns a synthetic Zipf distributed trace of IDs.
+func zipfTrace(length int, keys uint64) []string {
+	zipf := rand.NewZipf(rand.New(rand.NewSource(42)), 1.1, 1, keys-1)
+	trace := make([]string, length)
+
+	for i := range trace {
+		trace[i] = strconv.FormatUint(zipf.Uint64(), 10)
+	}
+
+	return trace
+}
+
+// BenchmarkGet_Parallel measures the throughput of concurrent
+// reads of a populated cache.
+func BenchmarkGet_Parallel(b *testing.B) {
+	const maxSize = 1000
+
+	trace := zipfTrace(1<<16, maxSize)
+
+	for name, newCache := range newBenchCaches() {
+		b.Run(name, func(b *testing.B) {
+			cache := newCache(maxSize)
+
+			for i := 0; i < maxSize; i++ {
+				_ = cache.AddByID(strconv.Itoa(i), strconv.Itoa(i))
+			}
+
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				i := rand.Intn(len(trace))
+
+				for pb.Next() {
+					_, _ = cache.Get(trace[i%len(trace)])
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkGetAdd_Parallel measures the throughput of concurrent
+// reads, adding every missing entry to the cache.
+func BenchmarkGetAdd_Parallel(b *testing.B) {
+	const maxSize = 1000
+
+	trace := zipfTrace(1<<16, 100*maxSize)
+
+	for name, newCache := range newBenchCaches() {
+		b.Run(name, func(b *testing.B) {
+			cache := newCache(maxSize)
+
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				i := rand.Intn(len(trace))
+
+				for pb.Next() {
+					id := trace[i%len(trace)]
+					if _, ok := cache.Get(id); !ok {
+						_ = cache.AddByID(id, id)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
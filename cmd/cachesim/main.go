@@ -0,0 +1,136 @@
+/*
+Command cachesim replays a trace of cache accesses against the caches of this module
+and reports the hit ratio, the byte hit ratio & the number of evictions per cache and capacity.
+
+Usage:
+
+	cachesim [flags] [trace file]
+
+The trace is read from the standard input if no file is given. Every line of the trace
+either contains only the key of the accessed entry or the key, the size in bytes and the
+timestamp separated by commas.
+*/
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/piccobit/generics/cachesim"
+)
+
+func main() {
+	policyNames := flag.String("policies", "", "comma separated list of the simulated caches (default all)")
+	capacityList := flag.String("capacities", "100,1000,10000", "comma separated list of the simulated capacities")
+	format := flag.String("format", "text", "format of the report, either 'text' or 'csv'")
+
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [trace file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if err := run(*policyNames, *capacityList, *format, flag.Args()); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
+
+func run(policyNames string, capacityList string, format string, args []string) error {
+	policies, err := selectPolicies(policyNames)
+	if err != nil {
+		return err
+	}
+
+	capacities, err := parseCapacities(capacityList)
+	if err != nil {
+		return err
+	}
+
+	var write func(io.Writer, []cachesim.Result) error
+
+	switch format {
+	case "text":
+		write = cachesim.WriteText
+	case "csv":
+		write = cachesim.WriteCSV
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	var input io.Reader = os.Stdin
+
+	if len(args) > 1 {
+		return fmt.Errorf("only a single trace file is supported")
+	}
+
+	if len(args) == 1 && args[0] != "-" {
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		input = file
+	}
+
+	trace, err := cachesim.ReadTrace(input)
+	if err != nil {
+		return err
+	}
+
+	return write(os.Stdout, cachesim.Simulate(trace, policies, capacities))
+}
+
+func selectPolicies(policyNames string) (map[string]cachesim.Factory, error) {
+	policies := cachesim.Policies()
+
+	if policyNames == "" {
+		return policies, nil
+	}
+
+	selected := make(map[string]cachesim.Factory)
+
+	for _, name := range strings.Split(policyNames, ",") {
+		name = strings.TrimSpace(name)
+
+		factory, ok := policies[name]
+		if !ok {
+			names := make([]string, 0, len(policies))
+
+			for known := range policies {
+				names = append(names, known)
+			}
+
+			sort.Strings(names)
+
+			return nil, fmt.Errorf("unknown policy %q, known policies are %s", name, strings.Join(names, ", "))
+		}
+
+		selected[name] = factory
+	}
+
+	return selected, nil
+}
+
+func parseCapacities(capacityList string) ([]int, error) {
+	var capacities []int
+
+	for _, value := range strings.Split(capacityList, ",") {
+		capacity, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || capacity <= 0 {
+			return nil, fmt.Errorf("invalid capacity %q", value)
+		}
+
+		capacities = append(capacities, capacity)
+	}
+
+	return capacities, nil
+}
@@ -0,0 +1,13 @@
+/*
+Package constraints holds the type constraints shared by the packages of this module.
+*/
+package constraints
+
+// Ordered is a constraint that permits any ordered type,
+// that means any type that supports the < operator.
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 |
+		~string
+}
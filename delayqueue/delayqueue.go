@@ -0,0 +1,325 @@
+/*
+Package delayqueue is a simple generic implementation of a delayed queue, that means
+every value becomes visible only after its due time and the values are retrieved
+in the order of their due times. Values with the same due time are retrieved in
+FIFO ('First In, First Out') order.
+*/
+package delayqueue
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers to the delayed queue.
+// It allows to replace the wall clock, e.g. in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of the 'time.Timer' functionality used
+// by the delayed queue.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemClock struct{}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return &systemTimer{timer: time.NewTimer(d)}
+}
+
+func (p *systemTimer) C() <-chan time.Time {
+	return p.timer.C
+}
+
+func (p *systemTimer) Stop() bool {
+	return p.timer.Stop()
+}
+
+type item[T any] struct {
+	due   time.Time
+	seq   uint64
+	value T
+}
+
+type items[T any] []item[T]
+
+func (p items[T]) Len() int {
+	return len(p)
+}
+
+func (p items[T]) Less(i, j int) bool {
+	if p[i].due.Equal(p[j].due) {
+		return p[i].seq < p[j].seq
+	}
+
+	return p[i].due.Before(p[j].due)
+}
+
+func (p items[T]) Swap(i, j int) {
+	p[i], p[j] = p[j], p[i]
+}
+
+func (p *items[T]) Push(x any) {
+	*p = append(*p, x.(item[T]))
+}
+
+func (p *items[T]) Pop() any {
+	old := *p
+	n := len(old)
+	value := old[n-1]
+	old[n-1] = item[T]{}
+	*p = old[:n-1]
+
+	return value
+}
+
+type DelayQueue[T any] struct {
+	content items[T]
+	maxSize int
+	seq     uint64
+	clock   Clock
+	changed chan struct{}
+	mutex   sync.RWMutex
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// Option configures optional behaviour of a delayed queue.
+type Option func(*options)
+
+type options struct {
+	clock Clock
+}
+
+// WithClock sets the clock used by the delayed queue.
+// The default clock is the wall clock.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+// New returns the pointer to a new delayed queue.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the queue. Setting this to 0
+// allows the queue to grow infinitely.
+func New[T any](maxSize int, opts ...Option) *DelayQueue[T] {
+	o := options{clock: systemClock{}}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	queue := DelayQueue[T]{
+		maxSize: maxSize,
+		clock:   o.clock,
+	}
+
+	return &queue
+}
+
+// PushAt pushes the given value on the queue, the value
+// becomes visible at the provided time.
+// An overflow error is returned in case the queue is
+// limited in its size and the push would overflow the queue.
+func (p *DelayQueue[T]) PushAt(value T, due time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize > 0 && len(p.content) >= p.maxSize {
+		return &OverflowError{}
+	}
+
+	heap.Push(&p.content, item[T]{
+		due:   due,
+		seq:   p.seq,
+		value: value,
+	})
+
+	p.seq++
+	p.notify()
+
+	return nil
+}
+
+// PushAfter pushes the given value on the queue, the value
+// becomes visible after the provided duration.
+// An overflow error is returned in case the queue is
+// limited in its size and the push would overflow the queue.
+func (p *DelayQueue[T]) PushAfter(value T, d time.Duration) error {
+	return p.PushAt(value, p.clock.Now().Add(d))
+}
+
+// Poll pops the value with the earliest due time if that time has
+// already passed and returns it to the caller. If the queue is empty
+// or no value is due yet an 'Underflow error' is returned.
+func (p *DelayQueue[T]) Poll() (T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.content) <= 0 || p.content[0].due.After(p.clock.Now()) {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.pop(), nil
+}
+
+// Take pops the value with the earliest due time and returns it to the caller.
+// If the queue is empty or no value is due yet, Take blocks until a value
+// becomes due or the context is cancelled, in which case the error of the
+// context is returned.
+func (p *DelayQueue[T]) Take(ctx context.Context) (T, error) {
+	for {
+		p.mutex.Lock()
+
+		if p.changed == nil {
+			p.changed = make(chan struct{})
+		}
+
+		changed := p.changed
+
+		var delay time.Duration
+
+		if len(p.content) > 0 {
+			delay = p.content[0].due.Sub(p.clock.Now())
+
+			if delay <= 0 {
+				value := p.pop()
+
+				p.mutex.Unlock()
+
+				return value, nil
+			}
+		}
+
+		empty := len(p.content) <= 0
+
+		p.mutex.Unlock()
+
+		if empty {
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				var ret T
+				return ret, ctx.Err()
+			}
+
+			continue
+		}
+
+		timer := p.clock.NewTimer(delay)
+
+		select {
+		case <-timer.C():
+		case <-changed:
+		case <-ctx.Done():
+			timer.Stop()
+
+			var ret T
+			return ret, ctx.Err()
+		}
+
+		timer.Stop()
+	}
+}
+
+// Length returns the number of queue elements,
+// regardless of their due times.
+func (p *DelayQueue[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.content)
+}
+
+// Due returns the number of queue elements whose due time has passed.
+func (p *DelayQueue[T]) Due() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	now := p.clock.Now()
+	due := 0
+
+	for _, queueItem := range p.content {
+		if !queueItem.due.After(now) {
+			due++
+		}
+	}
+
+	return due
+}
+
+// String implements the Stringer interface to provide a
+// textual representation of the queue content in due order.
+func (p *DelayQueue[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	sorted := make(items[T], len(p.content))
+	copy(sorted, p.content)
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	for i := 0; len(sorted) > 0; i++ {
+		if i > 0 {
+			str.WriteString(",")
+		}
+
+		queueItem := heap.Pop(&sorted).(item[T])
+
+		_, _ = fmt.Fprintf(&str, "%v", queueItem.value)
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// pop removes the value with the earliest due time and returns it.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DelayQueue[T]) pop() T {
+	queueItem := heap.Pop(&p.content).(item[T])
+
+	p.notify()
+
+	return queueItem.value
+}
+
+// notify wakes up all goroutines waiting for a modification of the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DelayQueue[T]) notify() {
+	if p.changed != nil {
+		close(p.changed)
+		p.changed = nil
+	}
+}
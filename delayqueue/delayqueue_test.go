@@ -0,0 +1,270 @@
+package delayqueue_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/piccobit/generics/delayqueue"
+)
+
+// fakeClock is a manually advanced clock, its timers fire when
+// the clock is advanced past their due time.
+type fakeClock struct {
+	now     time.Time
+	timers  []*fakeTimer
+	created *sync.Cond
+	mutex   sync.Mutex
+}
+
+type fakeTimer struct {
+	clock *fakeClock
+	due   time.Time
+	c     chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	clock := fakeClock{now: now}
+	clock.created = sync.NewCond(&clock.mutex)
+
+	return &clock
+}
+
+func (p *fakeClock) Now() time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.now
+}
+
+func (p *fakeClock) NewTimer(d time.Duration) delayqueue.Timer {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	timer := fakeTimer{clock: p, due: p.now.Add(d), c: make(chan time.Time, 1)}
+
+	if d <= 0 {
+		timer.c <- p.now
+	} else {
+		p.timers = append(p.timers, &timer)
+		p.created.Broadcast()
+	}
+
+	return &timer
+}
+
+// Advance moves the clock forward and fires all timers which became due.
+func (p *fakeClock) Advance(d time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.now = p.now.Add(d)
+
+	pending := p.timers[:0]
+
+	for _, timer := range p.timers {
+		if timer.due.After(p.now) {
+			pending = append(pending, timer)
+		} else {
+			timer.c <- p.now
+		}
+	}
+
+	p.timers = pending
+}
+
+// WaitForTimers blocks until at least 'n' timers are pending.
+func (p *fakeClock) WaitForTimers(n int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for len(p.timers) < n {
+		p.created.Wait()
+	}
+}
+
+func (p *fakeTimer) C() <-chan time.Time {
+	return p.c
+}
+
+func (p *fakeTimer) Stop() bool {
+	p.clock.mutex.Lock()
+	defer p.clock.mutex.Unlock()
+
+	for i, timer := range p.clock.timers {
+		if timer == p {
+			p.clock.timers = append(p.clock.timers[:i], p.clock.timers[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+func ExampleDelayQueue_PushAfter() {
+	var err error
+
+	clock := newFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	myStringQueue := delayqueue.New[string](0, delayqueue.WithClock(clock))
+
+	err = myStringQueue.PushAfter("World", 2*time.Second)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.PushAfter("Hello", time.Second)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myStringQueue)
+
+	_, err = myStringQueue.Poll()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	clock.Advance(time.Second)
+
+	fmt.Printf("Due: %d\n", myStringQueue.Due())
+
+	value, err := myStringQueue.Poll()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Poll: %s\n", value)
+	fmt.Printf("Length: %d\n", myStringQueue.Length())
+	// Output:
+	// Content: [Hello,World]
+	// ERROR: Underflow error
+	// Due: 1
+	// Poll: Hello
+	// Length: 1
+}
+
+func ExampleDelayQueue_PushAt() {
+	var err error
+
+	clock := newFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	myIntQueue := delayqueue.New[int](0, delayqueue.WithClock(clock))
+
+	for i, delay := range []int{3, 1, 2, 1} {
+		err = myIntQueue.PushAt(i, clock.Now().Add(time.Duration(delay)*time.Second))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	clock.Advance(3 * time.Second)
+
+	for myIntQueue.Length() > 0 {
+		value, err := myIntQueue.Take(context.Background())
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Take: %d\n", value)
+	}
+	// Output:
+	// Take: 1
+	// Take: 3
+	// Take: 2
+	// Take: 0
+}
+
+func ExampleDelayQueue_Take() {
+	var err error
+
+	myStringQueue := delayqueue.New[string](0)
+
+	err = myStringQueue.PushAfter("Hello", 10*time.Millisecond)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, err := myStringQueue.Take(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Take: %s\n", value)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = myStringQueue.Take(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Take: Hello
+	// ERROR: context deadline exceeded
+}
+
+func ExampleDelayQueue_Take_clock() {
+	var err error
+
+	clock := newFakeClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	myStringQueue := delayqueue.New[string](0, delayqueue.WithClock(clock))
+
+	err = myStringQueue.PushAfter("Hello", time.Minute)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	taken := make(chan string)
+
+	go func() {
+		value, err := myStringQueue.Take(context.Background())
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		taken <- value
+	}()
+
+	// Take blocks on its timer until the clock reaches the due time.
+	clock.WaitForTimers(1)
+	clock.Advance(30 * time.Second)
+
+	select {
+	case value := <-taken:
+		fmt.Printf("Take: %s (too early)\n", value)
+	default:
+		fmt.Printf("Length: %d\n", myStringQueue.Length())
+	}
+
+	clock.Advance(30 * time.Second)
+
+	fmt.Printf("Take: %s\n", <-taken)
+	fmt.Printf("Length: %d\n", myStringQueue.Length())
+	// Output:
+	// Length: 1
+	// Take: Hello
+	// Length: 0
+}
+
+func ExampleDelayQueue_overflow() {
+	var err error
+
+	myStringQueue := delayqueue.New[string](1)
+
+	err = myStringQueue.PushAfter("foo", time.Second)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.PushAfter("bar", time.Second)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// ERROR: Overflow error
+}
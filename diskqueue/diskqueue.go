@@ -0,0 +1,717 @@
+/*
+Package diskqueue is a simple generic implementation of a persistent FIFO ('First In, First Out')
+queue, that means the first input value is the one which will be also retrieved first.
+The values are appended to segmented log files in a directory and the read position is
+stored in a checkpoint file, so the content of the queue survives a restart of the process.
+*/
+package diskqueue
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/piccobit/generics/codec"
+)
+
+const (
+	segmentSuffix  = ".seg"
+	checkpointName = "checkpoint"
+	headerSize     = 8
+	checkpointSize = 20
+
+	// DefaultSegmentSize is the size in bytes after which a new segment file is started.
+	DefaultSegmentSize = 64 * 1024 * 1024
+)
+
+type DiskQueue[T any] struct {
+	dir         string
+	maxSize     int
+	codec       codec.Codec[T]
+	segmentSize int64
+	syncEvery   int
+	length      int
+	readSeg     uint64
+	readOff     int64
+	writeSeg    uint64
+	writeOff    int64
+	reader      *os.File
+	writer      *os.File
+	checkpoint  *os.File
+	unsynced    int
+	closed      bool
+	mutex       sync.RWMutex
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+type ClosedError struct{}
+
+// DecodeError is returned if the first record of the queue can't be decoded.
+// The record is skipped, so the queue continues with the following one.
+type DecodeError struct {
+	Err error
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+func (e *ClosedError) Error() string {
+	return "Closed error"
+}
+
+func (e *DecodeError) Error() string {
+	return "Decode error, record skipped: " + e.Err.Error()
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// Option configures optional behaviour of a disk queue.
+type Option func(*options)
+
+type options struct {
+	segmentSize int64
+	syncEvery   int
+}
+
+// WithSegmentSize sets the size in bytes after which a new segment file is started.
+// Consumed segment files are deleted, so smaller segments free disk space earlier.
+func WithSegmentSize(size int64) Option {
+	return func(o *options) {
+		o.segmentSize = size
+	}
+}
+
+// WithSyncEvery sets after how many writes the log and the checkpoint
+// are flushed to stable storage using fsync. Setting this to 1 syncs
+// after every operation, setting this to 0 leaves flushing to the
+// operating system. The default is 1.
+func WithSyncEvery(n int) Option {
+	return func(o *options) {
+		o.syncEvery = n
+	}
+}
+
+// Open opens or creates a disk queue stored in the provided directory.
+// The 'maxSize' parameter allows to specify a maximum size for the queue.
+// Setting this to 0 allows the queue to grow infinitely.
+// The 'valueCodec' parameter defines how values are stored on disk, if it is
+// nil the values are stored as JSON.
+// A record which was only partially written before a crash is discarded
+// together with all records following it in the same segment.
+func Open[T any](dir string, maxSize int, valueCodec codec.Codec[T], opts ...Option) (*DiskQueue[T], error) {
+	o := options{
+		segmentSize: DefaultSegmentSize,
+		syncEvery:   1,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if valueCodec == nil {
+		valueCodec = codec.JSONCodec[T]{}
+	}
+
+	queue := DiskQueue[T]{
+		dir:         dir,
+		maxSize:     maxSize,
+		codec:       valueCodec,
+		segmentSize: o.segmentSize,
+		syncEvery:   o.syncEvery,
+	}
+
+	if err := queue.recover(); err != nil {
+		queue.closeFiles()
+
+		return nil, err
+	}
+
+	return &queue, nil
+}
+
+// Push pushes the given arguments on the provided queue.
+// An overflow error is returned in case the queue is
+// limited in its size and the push would overflow the queue.
+// If writing fails, the records already written by the push are
+// removed again, so either all or none of the arguments are pushed.
+func (p *DiskQueue[T]) Push(args ...T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return &ClosedError{}
+	}
+
+	if p.maxSize > 0 && len(args) > 0 && p.length+len(args) > p.maxSize {
+		return &OverflowError{}
+	}
+
+	records := make([][]byte, 0, len(args))
+
+	for _, arg := range args {
+		payload, err := p.codec.Encode(arg)
+		if err != nil {
+			return fmt.Errorf("diskqueue: encode value: %w", err)
+		}
+
+		records = append(records, encodeRecord(payload))
+	}
+
+	seg, off, length := p.writeSeg, p.writeOff, p.length
+
+	for _, record := range records {
+		if p.writeOff > 0 && p.writeOff >= p.segmentSize {
+			if err := p.rotate(); err != nil {
+				return p.rollback(seg, off, length, err)
+			}
+		}
+
+		if _, err := p.writer.Write(record); err != nil {
+			return p.rollback(seg, off, length, fmt.Errorf("diskqueue: write record: %w", err))
+		}
+
+		p.writeOff += int64(len(record))
+		p.length++
+	}
+
+	return p.synced(len(records))
+}
+
+// Pop pops the first element of the queue and returns it to the caller.
+// If the queue is empty an 'Underflow error' is returned. If the element
+// can't be decoded, it is skipped and a 'Decode error' is returned.
+func (p *DiskQueue[T]) Pop() (T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var ret T
+
+	payload, size, err := p.read()
+	if err != nil {
+		return ret, err
+	}
+
+	value, err := p.decode(payload, size)
+	if err != nil {
+		return ret, err
+	}
+
+	if err := p.advance(size); err != nil {
+		return ret, err
+	}
+
+	return value, nil
+}
+
+// Drop drops the first element of the queue.
+// An underflow error is returned in case the queue is
+// already empty.
+func (p *DiskQueue[T]) Drop() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	_, size, err := p.read()
+	if err != nil {
+		return err
+	}
+
+	return p.advance(size)
+}
+
+// Peek gets the first element of the queue and returns it to the caller.
+// If the queue is empty an 'Underflow error' is returned. If the element
+// can't be decoded, it is skipped and a 'Decode error' is returned.
+func (p *DiskQueue[T]) Peek() (T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var ret T
+
+	payload, size, err := p.read()
+	if err != nil {
+		return ret, err
+	}
+
+	return p.decode(payload, size)
+}
+
+// Length returns the number of queue elements.
+func (p *DiskQueue[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.length
+}
+
+// Sync flushes the log and the checkpoint to stable storage.
+func (p *DiskQueue[T]) Sync() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return &ClosedError{}
+	}
+
+	return p.sync()
+}
+
+// Close flushes the queue to stable storage and closes its files.
+// The queue can't be used anymore after it has been closed.
+func (p *DiskQueue[T]) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	err := p.sync()
+
+	p.closeFiles()
+	p.closed = true
+
+	return err
+}
+
+// recover restores the state of the queue from the directory content.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) recover() error {
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return fmt.Errorf("diskqueue: create directory: %w", err)
+	}
+
+	segments, err := p.segments()
+	if err != nil {
+		return err
+	}
+
+	checkpoint, err := os.OpenFile(filepath.Join(p.dir, checkpointName), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("diskqueue: open checkpoint: %w", err)
+	}
+
+	p.checkpoint = checkpoint
+
+	readSeg, readOff, ok := p.readCheckpoint()
+
+	if len(segments) == 0 {
+		segments = append(segments, readSeg)
+	}
+
+	if !ok || readSeg < segments[0] || readSeg > segments[len(segments)-1] {
+		readSeg, readOff = segments[0], 0
+	}
+
+	p.readSeg, p.readOff = readSeg, readOff
+
+	for _, seg := range segments {
+		if seg < readSeg {
+			if err := os.Remove(p.segmentPath(seg)); err != nil {
+				return fmt.Errorf("diskqueue: remove segment: %w", err)
+			}
+
+			continue
+		}
+
+		count, end, err := p.scan(seg)
+		if err != nil {
+			return err
+		}
+
+		p.length += count
+		p.writeSeg, p.writeOff = seg, end
+	}
+
+	writer, err := os.OpenFile(p.segmentPath(p.writeSeg), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("diskqueue: open segment: %w", err)
+	}
+
+	p.writer = writer
+
+	return p.writeCheckpoint()
+}
+
+// scan validates the records of the provided segment, truncates the segment
+// after the last valid record and returns the number of unread records
+// together with the end offset of the valid records.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) scan(seg uint64) (int, int64, error) {
+	file, err := os.OpenFile(p.segmentPath(seg), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, 0, fmt.Errorf("diskqueue: open segment: %w", err)
+	}
+
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, 0, fmt.Errorf("diskqueue: stat segment: %w", err)
+	}
+
+	count := 0
+	off := int64(0)
+	aligned := seg != p.readSeg
+
+	for off < info.Size() {
+		_, size, err := readRecord(file, off, info.Size())
+		if err != nil {
+			break
+		}
+
+		if !aligned && off >= p.readOff {
+			p.readOff = off
+			aligned = true
+		}
+
+		if aligned {
+			count++
+		}
+
+		off += size
+	}
+
+	if !aligned {
+		p.readOff = off
+	}
+
+	if off < info.Size() {
+		if err := file.Truncate(off); err != nil {
+			return 0, 0, fmt.Errorf("diskqueue: truncate segment: %w", err)
+		}
+	}
+
+	return count, off, nil
+}
+
+// read returns the payload and the size of the first record of the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) read() ([]byte, int64, error) {
+	if p.closed {
+		return nil, 0, &ClosedError{}
+	}
+
+	if p.length <= 0 {
+		return nil, 0, &UnderflowError{}
+	}
+
+	if err := p.compact(); err != nil {
+		return nil, 0, err
+	}
+
+	if p.reader == nil {
+		reader, err := os.Open(p.segmentPath(p.readSeg))
+		if err != nil {
+			return nil, 0, fmt.Errorf("diskqueue: open segment: %w", err)
+		}
+
+		p.reader = reader
+	}
+
+	payload, size, err := readRecord(p.reader, p.readOff, math.MaxInt64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("diskqueue: read record: %w", err)
+	}
+
+	return payload, size, nil
+}
+
+// decode decodes the payload of the first record of the queue.
+// If decoding fails, the record is skipped.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) decode(payload []byte, size int64) (T, error) {
+	value, decodeErr := p.codec.Decode(payload)
+	if decodeErr == nil {
+		return value, nil
+	}
+
+	if err := p.advance(size); err != nil {
+		return value, err
+	}
+
+	return value, &DecodeError{Err: decodeErr}
+}
+
+// advance moves the read position behind the first record of the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) advance(size int64) error {
+	p.readOff += size
+	p.length--
+
+	if err := p.compact(); err != nil {
+		return err
+	}
+
+	if err := p.writeCheckpoint(); err != nil {
+		return err
+	}
+
+	return p.synced(1)
+}
+
+// compact moves the read position to the next segment and deletes the
+// consumed segment as long as the current one has been read completely.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) compact() error {
+	for p.readSeg < p.writeSeg {
+		info, err := os.Stat(p.segmentPath(p.readSeg))
+		if err != nil {
+			return fmt.Errorf("diskqueue: stat segment: %w", err)
+		}
+
+		if p.readOff < info.Size() {
+			return nil
+		}
+
+		if p.reader != nil {
+			_ = p.reader.Close()
+			p.reader = nil
+		}
+
+		if err := os.Remove(p.segmentPath(p.readSeg)); err != nil {
+			return fmt.Errorf("diskqueue: remove segment: %w", err)
+		}
+
+		p.readSeg++
+		p.readOff = 0
+
+		if err := p.writeCheckpoint(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rotate closes the current write segment and starts a new one.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) rotate() error {
+	if err := p.writer.Sync(); err != nil {
+		return fmt.Errorf("diskqueue: sync segment: %w", err)
+	}
+
+	if err := p.writer.Close(); err != nil {
+		return fmt.Errorf("diskqueue: close segment: %w", err)
+	}
+
+	writer, err := os.OpenFile(p.segmentPath(p.writeSeg+1), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("diskqueue: open segment: %w", err)
+	}
+
+	p.writer = writer
+	p.writeSeg++
+	p.writeOff = 0
+
+	return nil
+}
+
+// rollback removes the records written after the provided write position
+// and restores the provided length. The provided error is returned,
+// together with the error of the rollback if it fails as well.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) rollback(seg uint64, off int64, length int, err error) error {
+	_ = p.writer.Close()
+
+	for ; p.writeSeg > seg; p.writeSeg-- {
+		if rollbackErr := os.Remove(p.segmentPath(p.writeSeg)); rollbackErr != nil {
+			return fmt.Errorf("%w, rollback: remove segment: %v", err, rollbackErr)
+		}
+	}
+
+	p.writeOff, p.length = off, length
+
+	if rollbackErr := os.Truncate(p.segmentPath(seg), off); rollbackErr != nil {
+		return fmt.Errorf("%w, rollback: truncate segment: %v", err, rollbackErr)
+	}
+
+	writer, rollbackErr := os.OpenFile(p.segmentPath(seg), os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if rollbackErr != nil {
+		return fmt.Errorf("%w, rollback: open segment: %v", err, rollbackErr)
+	}
+
+	p.writer = writer
+
+	return err
+}
+
+// synced flushes the queue to stable storage if the sync policy requires it.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) synced(n int) error {
+	if p.syncEvery <= 0 {
+		return nil
+	}
+
+	p.unsynced += n
+
+	if p.unsynced < p.syncEvery {
+		return nil
+	}
+
+	return p.sync()
+}
+
+// sync flushes the log and the checkpoint to stable storage.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) sync() error {
+	p.unsynced = 0
+
+	if err := p.writer.Sync(); err != nil {
+		return fmt.Errorf("diskqueue: sync segment: %w", err)
+	}
+
+	if err := p.checkpoint.Sync(); err != nil {
+		return fmt.Errorf("diskqueue: sync checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// readCheckpoint returns the stored read position, the returned boolean
+// value indicates if a valid checkpoint was found.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *DiskQueue[T]) readCheckpoint() (uint64, int64, bool) {
+	buf := make([]byte, checkpointSize)
+
+	if _, err := p.checkpoint.ReadAt(buf, 0); err != nil {
+		return 0, 0, false
+	}
+
+	if crc32.ChecksumIEEE(buf[:16]) != binary.LittleEndian.Uint32(buf[16:]) {
+		return 0, 0, false
+	}
+
+	return binary.LittleEndian.Uint64(buf[0:8]), int64(binary.LittleEndian.Uint64(buf[8:16])), true
+}
+
+// writeCheckpoint stores the current read position.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *DiskQueue[T]) writeCheckpoint() error {
+	buf := make([]byte, checkpointSize)
+
+	binary.LittleEndian.PutUint64(buf[0:8], p.readSeg)
+	binary.LittleEndian.PutUint64(buf[8:16], uint64(p.readOff))
+	binary.LittleEndian.PutUint32(buf[16:], crc32.ChecksumIEEE(buf[:16]))
+
+	if _, err := p.checkpoint.WriteAt(buf, 0); err != nil {
+		return fmt.Errorf("diskqueue: write checkpoint: %w", err)
+	}
+
+	return nil
+}
+
+// segments returns the sorted numbers of the segment files in the directory.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *DiskQueue[T]) segments() ([]uint64, error) {
+	entries, err := os.ReadDir(p.dir)
+	if err != nil {
+		return nil, fmt.Errorf("diskqueue: read directory: %w", err)
+	}
+
+	var segments []uint64
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() || !strings.HasSuffix(name, segmentSuffix) {
+			continue
+		}
+
+		seg, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		segments = append(segments, seg)
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i] < segments[j]
+	})
+
+	return segments, nil
+}
+
+func (p *DiskQueue[T]) segmentPath(seg uint64) string {
+	return filepath.Join(p.dir, fmt.Sprintf("%020d%s", seg, segmentSuffix))
+}
+
+func (p *DiskQueue[T]) closeFiles() {
+	for _, file := range []*os.File{p.reader, p.writer, p.checkpoint} {
+		if file != nil {
+			_ = file.Close()
+		}
+	}
+
+	p.reader, p.writer, p.checkpoint = nil, nil, nil
+}
+
+// encodeRecord prefixes the payload with its length and CRC32 checksum.
+func encodeRecord(payload []byte) []byte {
+	record := make([]byte, headerSize+len(payload))
+
+	binary.LittleEndian.PutUint32(record[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(payload))
+	copy(record[headerSize:], payload)
+
+	return record
+}
+
+// readRecord reads the record at the provided offset and returns its payload
+// together with the size of the whole record. Records which would end
+// after the provided limit are reported as truncated.
+func readRecord(r io.ReaderAt, off int64, limit int64) ([]byte, int64, error) {
+	header := make([]byte, headerSize)
+
+	if _, err := r.ReadAt(header, off); err != nil {
+		return nil, 0, err
+	}
+
+	length := int64(binary.LittleEndian.Uint32(header[0:4]))
+
+	if off+headerSize+length > limit {
+		return nil, 0, io.ErrUnexpectedEOF
+	}
+
+	payload := make([]byte, length)
+
+	if _, err := r.ReadAt(payload, off+headerSize); err != nil {
+		return nil, 0, err
+	}
+
+	if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(header[4:8]) {
+		return nil, 0, errors.New("checksum mismatch")
+	}
+
+	return payload, int64(headerSize + len(payload)), nil
+}
@@ -0,0 +1,372 @@
+package diskqueue_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/piccobit/generics/codec"
+	"github.com/piccobit/generics/diskqueue"
+)
+
+type car struct {
+	Name       string
+	Colour     string
+	Horsepower int
+}
+
+func ExampleDiskQueue_Push() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringQueue, err := diskqueue.Open[string](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.Push("Hello", "World")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Length: %d\n", myStringQueue.Length())
+
+	err = myStringQueue.Close()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	myStringQueue, err = diskqueue.Open[string](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringQueue.Close()
+
+	value, err := myStringQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Pop: %s\n", value)
+	fmt.Printf("Length: %d\n", myStringQueue.Length())
+	// Output:
+	// Length: 2
+	// Pop: Hello
+	// Length: 1
+}
+
+func ExampleDiskQueue_Pop() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myCarQueue, err := diskqueue.Open[car](dir, 0, codec.GobCodec[car]{})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myCarQueue.Push(car{"VW", "blue", 60}, car{"Corvette", "red", 200})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, err := myCarQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Pop: %v\n", value)
+
+	// The read position survives a restart of the queue.
+	err = myCarQueue.Close()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	myCarQueue, err = diskqueue.Open[car](dir, 0, codec.GobCodec[car]{})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myCarQueue.Close()
+
+	value, err = myCarQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Pop: %v\n", value)
+
+	_, err = myCarQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Pop: {VW blue 60}
+	// Pop: {Corvette red 200}
+	// ERROR: Underflow error
+}
+
+func ExampleOpen_tornWrite() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myIntQueue, err := diskqueue.Open[int](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntQueue.Push(13, 42)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntQueue.Close()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	// Simulate a crash in the middle of writing a record.
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+
+	segment, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, _ = segment.Write([]byte{0x10, 0x00, 0x00, 0x00, 0xde, 0xad})
+	_ = segment.Close()
+
+	myIntQueue, err = diskqueue.Open[int](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myIntQueue.Close()
+
+	fmt.Printf("Length: %d\n", myIntQueue.Length())
+
+	err = myIntQueue.Push(7)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for myIntQueue.Length() > 0 {
+		value, err := myIntQueue.Pop()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Pop: %d\n", value)
+	}
+	// Output:
+	// Length: 2
+	// Pop: 13
+	// Pop: 42
+	// Pop: 7
+}
+
+func ExampleWithSegmentSize() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myIntQueue, err := diskqueue.Open[int](dir, 0, nil, diskqueue.WithSegmentSize(32), diskqueue.WithSyncEvery(0))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myIntQueue.Close()
+
+	for i := 1; i <= 10; i++ {
+		err = myIntQueue.Push(i)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	fmt.Printf("Segments: %d\n", len(segments))
+
+	err = myIntQueue.Drop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for i := 0; i < 8; i++ {
+		err = myIntQueue.Drop()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	segments, _ = filepath.Glob(filepath.Join(dir, "*.seg"))
+	fmt.Printf("Segments: %d\n", len(segments))
+
+	value, err := myIntQueue.Peek()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Peek: %d\n", value)
+	// Output:
+	// Segments: 3
+	// Segments: 1
+	// Peek: 10
+}
+
+func ExampleDiskQueue_overflow() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringQueue, err := diskqueue.Open[string](dir, 3, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringQueue.Close()
+
+	err = myStringQueue.Push("foo", "bar", "hello", "world")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// ERROR: Overflow error
+}
+
+func ExampleDiskQueue_Pop_decodeError() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringQueue, err := diskqueue.Open[string](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.Push("Hello")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_ = myStringQueue.Close()
+
+	// The queue is reopened with another type, so the first record can't be decoded.
+	myIntQueue, err := diskqueue.Open[int](dir, 0, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myIntQueue.Close()
+
+	err = myIntQueue.Push(42)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntQueue.Pop()
+
+	var decodeErr *diskqueue.DecodeError
+	fmt.Printf("Decode error: %t\n", errors.As(err, &decodeErr))
+
+	value, err := myIntQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Pop: %d\n", value)
+	// Output:
+	// Decode error: true
+	// Pop: 42
+}
+
+func ExampleDiskQueue_Push_rollback() {
+	var err error
+
+	dir, err := os.MkdirTemp("", "diskqueue")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	// Each segment holds two records of a single character.
+	myStringQueue, err := diskqueue.Open[string](dir, 0, nil, diskqueue.WithSegmentSize(20))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringQueue.Close()
+
+	err = myStringQueue.Push("a")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	// A directory in place of the next segment lets the push fail after its first record.
+	next := filepath.Join(dir, fmt.Sprintf("%020d.seg", 1))
+
+	err = os.Mkdir(next, 0o755)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.Push("b", "c")
+	fmt.Printf("Failed: %t, Length: %d\n", err != nil, myStringQueue.Length())
+
+	_ = os.Remove(next)
+
+	err = myStringQueue.Push("b", "c")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for myStringQueue.Length() > 0 {
+		value, err := myStringQueue.Pop()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Pop: %s\n", value)
+	}
+	// Output:
+	// Failed: true, Length: 1
+	// Pop: a
+	// Pop: b
+	// Pop: c
+}
@@ -0,0 +1,230 @@
+/*
+Package event is a simple generic implementation of typed change notifications,
+which are used by the containers of this module to inform subscribers about
+their modifications. The delivery never blocks the publishing container, events
+for subscribers which are too slow are dropped according to their drop policy.
+*/
+package event
+
+import (
+	"context"
+	"sync"
+)
+
+// Kind defines the type of modification an event reports.
+type Kind int
+
+const (
+	// Pushed reports an element pushed on a stack or a queue.
+	Pushed Kind = iota
+	// Popped reports an element popped from a stack or a queue.
+	Popped
+	// Dropped reports an element dropped from a stack or a queue.
+	Dropped
+	// Added reports an entry added to a cache.
+	Added
+	// Updated reports an entry of a cache which was added again.
+	Updated
+	// Evicted reports an entry removed from a cache to make place for another one.
+	Evicted
+	// Removed reports an entry removed from a cache explicitly,
+	// e.g. by ID, by prefix or by tag.
+	Removed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Pushed:
+		return "Pushed"
+	case Popped:
+		return "Popped"
+	case Dropped:
+		return "Dropped"
+	case Added:
+		return "Added"
+	case Updated:
+		return "Updated"
+	case Evicted:
+		return "Evicted"
+	case Removed:
+		return "Removed"
+	default:
+		return "Unknown"
+	}
+}
+
+// Event reports a modification of a container. The 'ID' field
+// is only set by caches and holds the ID of the affected entry.
+type Event[T any] struct {
+	Kind  Kind
+	ID    string
+	Value T
+}
+
+// DropPolicy defines which events are dropped if the
+// buffer of a subscriber is full.
+type DropPolicy int
+
+const (
+	// DropNewest drops the event which could not be delivered.
+	DropNewest DropPolicy = iota
+	// DropOldest drops the oldest buffered event to make place for the new one.
+	DropOldest
+)
+
+const defaultBufferSize = 64
+
+// Option configures optional behaviour of a subscription.
+type Option func(*options)
+
+type options struct {
+	bufferSize int
+	policy     DropPolicy
+}
+
+// WithBufferSize sets the number of events buffered for a subscriber.
+// The default buffer size is 64.
+func WithBufferSize(size int) Option {
+	return func(o *options) {
+		o.bufferSize = size
+	}
+}
+
+// WithDropPolicy sets the policy used if the buffer of a subscriber
+// is full. The default policy is 'DropNewest'.
+func WithDropPolicy(policy DropPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+type subscriber[T any] struct {
+	events chan Event[T]
+	policy DropPolicy
+}
+
+// Broker distributes the published events to all subscribers.
+// The zero value of a broker is ready to use.
+type Broker[T any] struct {
+	subscribers map[*subscriber[T]]struct{}
+	dropped     uint
+	mutex       sync.RWMutex
+}
+
+// Subscribe returns a channel delivering all events published after the
+// subscription. Events are never delivered blocking, the optional 'opts'
+// parameters define how many events are buffered for the subscriber and
+// which events are dropped if the buffer is full.
+// A goroutine waits for the context to be cancelled and then ends the
+// subscription & closes the channel. With a context which is never
+// cancelled, e.g. 'context.Background()', the subscription and its
+// goroutine are kept forever.
+func (p *Broker[T]) Subscribe(ctx context.Context, opts ...Option) <-chan Event[T] {
+	o := options{bufferSize: defaultBufferSize}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.bufferSize < 1 {
+		o.bufferSize = 1
+	}
+
+	sub := &subscriber[T]{
+		events: make(chan Event[T], o.bufferSize),
+		policy: o.policy,
+	}
+
+	p.mutex.Lock()
+
+	if p.subscribers == nil {
+		p.subscribers = make(map[*subscriber[T]]struct{})
+	}
+
+	p.subscribers[sub] = struct{}{}
+
+	p.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		p.mutex.Lock()
+		defer p.mutex.Unlock()
+
+		delete(p.subscribers, sub)
+		close(sub.events)
+	}()
+
+	return sub.events
+}
+
+// Publish delivers the provided event to all subscribers without blocking.
+func (p *Broker[T]) Publish(e Event[T]) {
+	p.mutex.RLock()
+
+	if len(p.subscribers) == 0 {
+		p.mutex.RUnlock()
+
+		return
+	}
+
+	dropped := uint(0)
+
+	for sub := range p.subscribers {
+		if !sub.deliver(e) {
+			dropped++
+		}
+	}
+
+	p.mutex.RUnlock()
+
+	if dropped > 0 {
+		p.mutex.Lock()
+		p.dropped += dropped
+		p.mutex.Unlock()
+	}
+}
+
+// Active reports whether there are any subscribers, so that
+// publishers can skip preparing events nobody receives.
+func (p *Broker[T]) Active() bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.subscribers) > 0
+}
+
+// Dropped returns the number of events which could not be
+// delivered because the buffer of a subscriber was full.
+func (p *Broker[T]) Dropped() uint {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.dropped
+}
+
+// deliver sends the event to the subscriber and reports
+// whether no event had to be dropped.
+func (p *subscriber[T]) deliver(e Event[T]) bool {
+	select {
+	case p.events <- e:
+		return true
+	default:
+	}
+
+	if p.policy == DropNewest {
+		return false
+	}
+
+	select {
+	case <-p.events:
+	default:
+	}
+
+	select {
+	case p.events <- e:
+	default:
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package event_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/piccobit/generics/event"
+)
+
+func ExampleBroker_Subscribe() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var broker event.Broker[string]
+
+	events := broker.Subscribe(ctx)
+
+	broker.Publish(event.Event[string]{Kind: event.Added, ID: "foo", Value: "Hello"})
+	broker.Publish(event.Event[string]{Kind: event.Evicted, ID: "foo", Value: "Hello"})
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s %s: %s\n", e.Kind, e.ID, e.Value)
+	}
+	// Output:
+	// Added foo: Hello
+	// Evicted foo: Hello
+}
+
+func ExampleWithDropPolicy() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var broker event.Broker[int]
+
+	newest := broker.Subscribe(ctx, event.WithBufferSize(2))
+	oldest := broker.Subscribe(ctx, event.WithBufferSize(2), event.WithDropPolicy(event.DropOldest))
+
+	for i := 1; i <= 4; i++ {
+		broker.Publish(event.Event[int]{Kind: event.Pushed, Value: i})
+	}
+
+	cancel()
+
+	for e := range newest {
+		fmt.Printf("DropNewest: %d\n", e.Value)
+	}
+
+	for e := range oldest {
+		fmt.Printf("DropOldest: %d\n", e.Value)
+	}
+
+	fmt.Printf("Dropped: %d\n", broker.Dropped())
+	// Output:
+	// DropNewest: 1
+	// DropNewest: 2
+	// DropOldest: 3
+	// DropOldest: 4
+	// Dropped: 4
+}
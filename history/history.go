@@ -0,0 +1,293 @@
+/*
+Package history is a simple implementation of an undo/redo history built on two stacks.
+Commands are applied using Do and can be reverted and applied again using Undo and Redo.
+Several commands can be grouped into a transaction, which is undone and redone as a whole.
+*/
+package history
+
+import (
+	"sync"
+
+	"github.com/piccobit/generics/stack"
+)
+
+// Command is a reversible operation managed by the history.
+type Command interface {
+	Apply() error
+	Revert() error
+}
+
+type funcCommand struct {
+	apply  func() error
+	revert func() error
+}
+
+func (p *funcCommand) Apply() error {
+	return p.apply()
+}
+
+func (p *funcCommand) Revert() error {
+	return p.revert()
+}
+
+// Func returns a command calling the provided functions.
+func Func(apply func() error, revert func() error) Command {
+	return &funcCommand{apply: apply, revert: revert}
+}
+
+type group struct {
+	commands []Command
+}
+
+// Group returns a command applying the provided commands in the given order
+// and reverting them in the reversed order. If a command fails, the commands
+// already processed are rolled back and the error is returned.
+func Group(commands ...Command) Command {
+	return &group{commands: commands}
+}
+
+func (p *group) Apply() error {
+	for i, command := range p.commands {
+		if err := command.Apply(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				_ = p.commands[j].Revert()
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *group) Revert() error {
+	for i := len(p.commands) - 1; i >= 0; i-- {
+		if err := p.commands[i].Revert(); err != nil {
+			for j := i + 1; j < len(p.commands); j++ {
+				_ = p.commands[j].Apply()
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+type History struct {
+	undo        *stack.Stack[Command]
+	redo        *stack.Stack[Command]
+	maxDepth    int
+	transaction *group
+	nesting     int
+	mutex       sync.Mutex
+}
+
+type UnderflowError struct{}
+type TransactionError struct{}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *TransactionError) Error() string {
+	return "Transaction error"
+}
+
+// New returns the pointer to a new history.
+// The 'maxDepth' parameter allows to specify the maximum number of
+// commands which can be undone. If the limit is reached, the oldest
+// command is dropped. Setting this to 0 allows the history to
+// grow infinitely.
+func New(maxDepth int) *History {
+	history := History{
+		undo:     stack.New[Command](0),
+		redo:     stack.New[Command](0),
+		maxDepth: maxDepth,
+	}
+
+	return &history
+}
+
+// Do applies the provided command and records it in the history.
+// Applying a new command clears the commands which could be redone.
+// Inside a transaction the command is added to the transaction.
+// If the command fails, it is not recorded and the error is returned.
+func (p *History) Do(command Command) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if err := command.Apply(); err != nil {
+		return err
+	}
+
+	if p.transaction != nil {
+		p.transaction.commands = append(p.transaction.commands, command)
+
+		return nil
+	}
+
+	p.record(command)
+
+	return nil
+}
+
+// Undo reverts the last applied command.
+// An underflow error is returned in case there is nothing to undo,
+// a transaction error is returned in case a transaction is open.
+func (p *History) Undo() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.transaction != nil {
+		return &TransactionError{}
+	}
+
+	command, err := p.undo.Pop()
+	if err != nil {
+		return &UnderflowError{}
+	}
+
+	if err := command.Revert(); err != nil {
+		_ = p.undo.Push(command)
+
+		return err
+	}
+
+	return p.redo.Push(command)
+}
+
+// Redo applies the last undone command again.
+// An underflow error is returned in case there is nothing to redo,
+// a transaction error is returned in case a transaction is open.
+func (p *History) Redo() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.transaction != nil {
+		return &TransactionError{}
+	}
+
+	command, err := p.redo.Pop()
+	if err != nil {
+		return &UnderflowError{}
+	}
+
+	if err := command.Apply(); err != nil {
+		_ = p.redo.Push(command)
+
+		return err
+	}
+
+	p.push(command)
+
+	return nil
+}
+
+// CanUndo reports whether there is a command which can be undone.
+func (p *History) CanUndo() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.transaction == nil && p.undo.Length() > 0
+}
+
+// CanRedo reports whether there is a command which can be redone.
+func (p *History) CanRedo() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.transaction == nil && p.redo.Length() > 0
+}
+
+// Begin opens a transaction. All commands applied until the matching
+// Commit are undone and redone as a single command.
+// Transactions can be nested, only the outermost transaction is recorded.
+func (p *History) Begin() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.transaction == nil {
+		p.transaction = &group{}
+	}
+
+	p.nesting++
+}
+
+// Commit closes the current transaction. If it is the outermost one,
+// its commands are recorded in the history as a single command.
+// A transaction error is returned in case no transaction is open.
+func (p *History) Commit() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.transaction == nil {
+		return &TransactionError{}
+	}
+
+	p.nesting--
+
+	if p.nesting > 0 {
+		return nil
+	}
+
+	transaction := p.transaction
+	p.transaction = nil
+
+	if len(transaction.commands) > 0 {
+		p.record(transaction)
+	}
+
+	return nil
+}
+
+// Rollback reverts all commands applied since the outermost transaction
+// was opened and closes all open transactions.
+// A transaction error is returned in case no transaction is open.
+func (p *History) Rollback() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.transaction == nil {
+		return &TransactionError{}
+	}
+
+	transaction := p.transaction
+	p.transaction = nil
+	p.nesting = 0
+
+	return transaction.Revert()
+}
+
+// Length returns the number of commands which can be undone
+// and the number of commands which can be redone.
+func (p *History) Length() (int, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.undo.Length(), p.redo.Length()
+}
+
+// record adds a newly applied command and clears the redo stack.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *History) record(command Command) {
+	p.redo.PopAll()
+	p.push(command)
+}
+
+// push adds the provided command to the undo stack and drops
+// the oldest command if the maximum depth is exceeded.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *History) push(command Command) {
+	if p.maxDepth > 0 && p.undo.Length() >= p.maxDepth {
+		commands := p.undo.PopAll()
+
+		for i := p.maxDepth - 2; i >= 0; i-- {
+			_ = p.undo.Push(commands[i])
+		}
+	}
+
+	_ = p.undo.Push(command)
+}
@@ -0,0 +1,167 @@
+package history_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/piccobit/generics/history"
+)
+
+type document struct {
+	text strings.Builder
+}
+
+func (p *document) insert(s string) history.Command {
+	return history.Func(
+		func() error {
+			p.text.WriteString(s)
+
+			return nil
+		},
+		func() error {
+			content := p.text.String()
+
+			p.text.Reset()
+			p.text.WriteString(strings.TrimSuffix(content, s))
+
+			return nil
+		},
+	)
+}
+
+func ExampleHistory_Undo() {
+	var err error
+
+	doc := &document{}
+	myHistory := history.New(0)
+
+	for _, s := range []string{"Hello", ", ", "World"} {
+		err = myHistory.Do(doc.insert(s))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+
+	err = myHistory.Undo()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+
+	err = myHistory.Redo()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+	fmt.Printf("CanUndo: %v, CanRedo: %v\n", myHistory.CanUndo(), myHistory.CanRedo())
+	// Output:
+	// Text: "Hello, World"
+	// Text: "Hello, "
+	// Text: "Hello, World"
+	// CanUndo: true, CanRedo: false
+}
+
+func ExampleHistory_Begin() {
+	var err error
+
+	doc := &document{}
+	myHistory := history.New(0)
+
+	err = myHistory.Do(doc.insert("Hello"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	myHistory.Begin()
+
+	for _, s := range []string{", ", "World"} {
+		err = myHistory.Do(doc.insert(s))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	err = myHistory.Undo()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	err = myHistory.Commit()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+
+	err = myHistory.Undo()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+	// Output:
+	// ERROR: Transaction error
+	// Text: "Hello, World"
+	// Text: "Hello"
+}
+
+func ExampleHistory_Rollback() {
+	var err error
+
+	doc := &document{}
+	myHistory := history.New(0)
+
+	myHistory.Begin()
+
+	err = myHistory.Do(doc.insert("Hello"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myHistory.Rollback()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+	fmt.Printf("CanUndo: %v\n", myHistory.CanUndo())
+	// Output:
+	// Text: ""
+	// CanUndo: false
+}
+
+func ExampleNew_maxDepth() {
+	var err error
+
+	doc := &document{}
+	myHistory := history.New(2)
+
+	for _, s := range []string{"a", "b", "c"} {
+		err = myHistory.Do(doc.insert(s))
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	for myHistory.CanUndo() {
+		err = myHistory.Undo()
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Text: %q\n", doc.text.String())
+
+	err = myHistory.Undo()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Text: "a"
+	// ERROR: Underflow error
+}
@@ -0,0 +1,142 @@
+package httpcache
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// directives holds the parsed directives of a Cache-Control header.
+type directives map[string]string
+
+// parseCacheControl parses the Cache-Control header fields of the provided header.
+func parseCacheControl(header http.Header) directives {
+	d := directives{}
+
+	for _, field := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(field, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			name, value, _ := strings.Cut(part, "=")
+			d[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+
+	return d
+}
+
+// has checks if the provided directive is present.
+func (d directives) has(name string) bool {
+	_, ok := d[name]
+
+	return ok
+}
+
+// seconds returns the value of the provided directive as duration.
+// If the directive is missing or invalid 'false' is returned.
+func (d directives) seconds(name string) (time.Duration, bool) {
+	value, ok := d[name]
+	if !ok {
+		return 0, false
+	}
+
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+
+	return time.Duration(seconds) * time.Second, true
+}
+
+// heuristicStatus holds the status codes which are cacheable by default,
+// RFC 9111 section 4.2.2.
+var heuristicStatus = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusPermanentRedirect:    true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// lifetime returns the freshness lifetime of the provided entry,
+// RFC 9111 section 4.2.1. If the lifetime is neither explicit nor
+// can be derived heuristically 'false' is returned.
+func lifetime(entry *Entry) (time.Duration, bool) {
+	d := parseCacheControl(entry.Header)
+
+	if maxAge, ok := d.seconds("max-age"); ok {
+		return maxAge, true
+	}
+
+	date := responseDate(entry)
+
+	if expires := entry.Header.Get("Expires"); expires != "" {
+		// An invalid date, e.g. '0', represents a time in the past.
+		expiresTime, err := http.ParseTime(expires)
+		if err != nil || expiresTime.Before(date) {
+			return 0, true
+		}
+
+		return expiresTime.Sub(date), true
+	}
+
+	if !heuristicStatus[entry.StatusCode] {
+		return 0, false
+	}
+
+	// A tenth of the time since the last modification,
+	// as suggested by RFC 9111 section 4.2.2.
+	if lastModified, err := http.ParseTime(entry.Header.Get("Last-Modified")); err == nil && lastModified.Before(date) {
+		return date.Sub(lastModified) / 10, true
+	}
+
+	return 0, false
+}
+
+// age returns the current age of the provided entry, RFC 9111 section 4.2.3.
+func age(entry *Entry, now time.Time) time.Duration {
+	apparentAge := entry.ResponseTime.Sub(responseDate(entry))
+	if apparentAge < 0 {
+		apparentAge = 0
+	}
+
+	var ageValue time.Duration
+
+	if seconds, err := strconv.ParseInt(entry.Header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+
+	correctedAge := ageValue + entry.ResponseTime.Sub(entry.RequestTime)
+
+	initialAge := apparentAge
+	if correctedAge > initialAge {
+		initialAge = correctedAge
+	}
+
+	return initialAge + now.Sub(entry.ResponseTime)
+}
+
+// responseDate returns the Date header of the provided entry,
+// or the time it was received if the header is missing.
+func responseDate(entry *Entry) time.Time {
+	if date, err := http.ParseTime(entry.Header.Get("Date")); err == nil {
+		return date
+	}
+
+	return entry.ResponseTime
+}
+
+// hasValidator checks if the provided header allows to revalidate the response.
+func hasValidator(header http.Header) bool {
+	return header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+}
@@ -0,0 +1,549 @@
+/*
+Package httpcache implements an HTTP client cache as 'http.RoundTripper', following the
+semantics of RFC 9111 for a private cache. Responses to GET requests are stored in a cache
+backend, an LRU cache by default, and reused as long as they are fresh according to their
+'Cache-Control: max-age', 'Expires' or 'Last-Modified' headers. Stale responses with an
+'ETag' or 'Last-Modified' header are revalidated using a conditional request, a '304 Not
+Modified' answer updates the stored response. Responses selected by 'Vary' are stored per
+variant, 'no-store' responses & requests bypass the cache, and successful unsafe requests,
+e.g. POST, invalidate the stored responses of their URL.
+*/
+package httpcache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/piccobit/generics/lrucache"
+)
+
+const (
+	// DefaultMaxEntries is the maximum size of the default backend.
+	DefaultMaxEntries = 1024
+
+	// DefaultMaxBodySize is the default size limit of stored bodies in bytes.
+	DefaultMaxBodySize = 8 * 1024 * 1024
+
+	// XFromCache is the header set on responses served from the cache.
+	XFromCache = "X-From-Cache"
+)
+
+// Entry is a stored response.
+// Entries with the status code 0 only record the variants of a URL.
+type Entry struct {
+	StatusCode   int
+	Header       http.Header
+	Body         []byte
+	RequestTime  time.Time
+	ResponseTime time.Time
+	// Vary holds the request headers selecting the variants of a URL,
+	// Variants the keys of the stored variants.
+	Vary     []string
+	Variants []string
+}
+
+// Backend stores the entries of the cache.
+// The LRU & the LFU cache of this module can be used.
+type Backend interface {
+	Get(id string) (Entry, bool)
+	AddByID(id string, arg Entry) error
+	Remove(id string) bool
+}
+
+// Clock provides the current time to the cache.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Stats holds the statistics of the cache.
+type Stats struct {
+	// Hits counts the fresh responses served from the cache.
+	Hits uint
+	// Misses counts the requests without a usable stored response.
+	Misses uint
+	// Revalidations counts the conditional requests,
+	// NotModified the ones answered by '304 Not Modified'.
+	Revalidations uint
+	NotModified   uint
+	// Stores counts the stored responses.
+	Stores uint
+	// Invalidations counts the URLs invalidated by unsafe requests.
+	Invalidations uint
+}
+
+// Option configures optional behaviour of the cache.
+type Option func(*options)
+
+type options struct {
+	backend     Backend
+	maxBodySize int64
+	clock       Clock
+}
+
+// WithBackend sets the backend storing the entries.
+// By default an LRU cache with 'DefaultMaxEntries' entries is used.
+func WithBackend(backend Backend) Option {
+	return func(o *options) {
+		o.backend = backend
+	}
+}
+
+// WithMaxBodySize sets the size limit of stored bodies in bytes, larger
+// responses are passed through. The default is 'DefaultMaxBodySize'.
+func WithMaxBodySize(maxBodySize int64) Option {
+	return func(o *options) {
+		o.maxBodySize = maxBodySize
+	}
+}
+
+// WithClock sets the clock used to determine the age of the responses.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+type Transport struct {
+	next        http.RoundTripper
+	backend     Backend
+	maxBodySize int64
+	clock       Clock
+	// promote is set for LRU backends, whose reads don't
+	// update the recency of the entries.
+	promote bool
+	stats   Stats
+	// entriesMutex serializes the accesses of the markers & their variants,
+	// mutex protects the statistics.
+	entriesMutex sync.Mutex
+	mutex        sync.Mutex
+}
+
+// New returns the pointer to a new caching transport sending the requests
+// which can't be answered from the cache using the provided transport.
+// If the provided transport is nil, the default transport is used.
+// The optional 'opts' parameters allow to change the backend,
+// the size limit of the bodies and the clock.
+func New(next http.RoundTripper, opts ...Option) *Transport {
+	o := options{
+		maxBodySize: DefaultMaxBodySize,
+		clock:       systemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if next == nil {
+		next = http.DefaultTransport
+	}
+
+	if o.backend == nil {
+		o.backend = lrucache.New[Entry](DefaultMaxEntries)
+	}
+
+	transport := Transport{
+		next:        next,
+		backend:     o.backend,
+		maxBodySize: o.maxBodySize,
+		clock:       o.clock,
+	}
+
+	_, transport.promote = o.backend.(*lrucache.LRUCache[Entry])
+
+	return &transport
+}
+
+// Client returns a new HTTP client using the transport.
+func (p *Transport) Client() *http.Client {
+	return &http.Client{Transport: p}
+}
+
+// Stats returns the statistics of the cache.
+func (p *Transport) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.stats
+}
+
+// RoundTrip implements 'http.RoundTripper' answering the request from
+// the cache if possible.
+func (p *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return p.forward(req)
+	}
+
+	requestDirectives := parseCacheControl(req.Header)
+
+	// Conditional & range requests of the client are passed through.
+	if requestDirectives.has("no-store") || req.Header.Get("Range") != "" ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		p.count(&p.stats.Misses)
+
+		return p.next.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	now := p.clock.Now()
+
+	entry, ok := p.lookup(key, req)
+	if ok {
+		currentAge := age(&entry, now)
+		freshness, _ := lifetime(&entry)
+		fresh := currentAge < freshness
+
+		if maxAge, ok := requestDirectives.seconds("max-age"); ok && currentAge > maxAge {
+			fresh = false
+		}
+
+		if requestDirectives.has("no-cache") || parseCacheControl(entry.Header).has("no-cache") {
+			fresh = false
+		}
+
+		if fresh {
+			p.count(&p.stats.Hits)
+
+			return response(req, &entry, currentAge), nil
+		}
+
+		if hasValidator(entry.Header) && !requestDirectives.has("only-if-cached") {
+			return p.revalidate(req, key, &entry, now)
+		}
+	}
+
+	p.count(&p.stats.Misses)
+
+	if requestDirectives.has("only-if-cached") {
+		return &http.Response{
+			Status:     "504 " + http.StatusText(http.StatusGatewayTimeout),
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := p.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.store(req, key, resp, now)
+}
+
+// forward sends a request with a method other than GET. The stored responses
+// of the URL are invalidated by successful unsafe requests.
+func (p *Transport) forward(req *http.Request) (*http.Response, error) {
+	resp, err := p.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.Method {
+	case http.MethodHead, http.MethodOptions, http.MethodTrace:
+	default:
+		if resp.StatusCode < http.StatusBadRequest {
+			p.invalidate(req.URL.String())
+		}
+	}
+
+	return resp, nil
+}
+
+// revalidate sends a conditional request for the provided stale entry.
+func (p *Transport) revalidate(req *http.Request, key string, entry *Entry, requestTime time.Time) (*http.Response, error) {
+	p.count(&p.stats.Revalidations)
+
+	conditional := req.Clone(req.Context())
+
+	if etag := entry.Header.Get("ETag"); etag != "" {
+		conditional.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+		conditional.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := p.next.RoundTrip(conditional)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusNotModified {
+		return p.store(req, key, resp, requestTime)
+	}
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	p.count(&p.stats.NotModified)
+
+	// The headers of the 304 response replace the stored ones,
+	// RFC 9111 section 3.2.
+	updated := *entry
+	updated.Header = entry.Header.Clone()
+	updated.RequestTime = requestTime
+	updated.ResponseTime = p.clock.Now()
+
+	for name, values := range resp.Header {
+		if name != "Content-Length" {
+			updated.Header[name] = values
+		}
+	}
+
+	p.save(key, req, updated)
+
+	return response(req, &updated, age(&updated, updated.ResponseTime)), nil
+}
+
+// store stores the provided response if it is allowed to & returns it
+// with a body which can be read again.
+func (p *Transport) store(req *http.Request, key string, resp *http.Response, requestTime time.Time) (*http.Response, error) {
+	entry := Entry{
+		StatusCode:   resp.StatusCode,
+		Header:       resp.Header.Clone(),
+		RequestTime:  requestTime,
+		ResponseTime: p.clock.Now(),
+	}
+
+	if !storable(&entry) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, p.maxBodySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+
+		return nil, err
+	}
+
+	if int64(len(body)) > p.maxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+
+		return resp, nil
+	}
+
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	entry.Body = body
+	p.save(key, req, entry)
+	p.count(&p.stats.Stores)
+
+	return resp, nil
+}
+
+// lookup returns the entry stored for the provided key
+// and the variant selected by the provided request.
+// Found entries are promoted in LRU backends.
+func (p *Transport) lookup(key string, req *http.Request) (Entry, bool) {
+	p.entriesMutex.Lock()
+	defer p.entriesMutex.Unlock()
+
+	entry, ok := p.backend.Get(key)
+	if !ok {
+		return entry, false
+	}
+
+	p.touch(key, entry)
+
+	if entry.StatusCode != 0 {
+		return entry, true
+	}
+
+	variant := key + variantKey(entry.Vary, req.Header)
+
+	entry, ok = p.backend.Get(variant)
+	if ok {
+		p.touch(variant, entry)
+	}
+
+	return entry, ok
+}
+
+// touch marks the provided entry as recently used by adding it again,
+// if the backend is an LRU cache.
+// This function is only used internally and expects the entries mutex
+// to be locked, so the entry can't be replaced in the meantime.
+func (p *Transport) touch(key string, entry Entry) {
+	if p.promote {
+		_ = p.backend.AddByID(key, entry)
+	}
+}
+
+// save stores the provided entry, responses with a 'Vary' header
+// are stored per variant, referenced by a marker entry.
+func (p *Transport) save(key string, req *http.Request, entry Entry) {
+	p.entriesMutex.Lock()
+	defer p.entriesMutex.Unlock()
+
+	names := varyNames(entry.Header)
+	old, ok := p.backend.Get(key)
+	isMarker := ok && old.StatusCode == 0
+
+	if len(names) == 0 {
+		// The variants of a replaced marker can't be reached anymore.
+		if isMarker {
+			for _, oldVariant := range old.Variants {
+				p.backend.Remove(oldVariant)
+			}
+		}
+
+		p.put(key, entry)
+
+		return
+	}
+
+	variant := key + variantKey(names, req.Header)
+	marker := Entry{Vary: names, Variants: []string{variant}}
+
+	if isMarker {
+		if strings.Join(old.Vary, ",") == strings.Join(names, ",") {
+			for _, oldVariant := range old.Variants {
+				if oldVariant != variant {
+					marker.Variants = append(marker.Variants, oldVariant)
+				}
+			}
+		} else {
+			for _, oldVariant := range old.Variants {
+				p.backend.Remove(oldVariant)
+			}
+		}
+	}
+
+	entry.Vary = names
+	p.put(key, marker)
+	p.put(variant, entry)
+}
+
+// put adds or replaces the entry with the provided key in the backend.
+func (p *Transport) put(key string, entry Entry) {
+	if err := p.backend.AddByID(key, entry); err != nil {
+		// Backends like the LFU cache refuse to replace entries.
+		p.backend.Remove(key)
+		_ = p.backend.AddByID(key, entry)
+	}
+}
+
+// invalidate removes the entries stored for the provided key.
+func (p *Transport) invalidate(key string) {
+	p.entriesMutex.Lock()
+	defer p.entriesMutex.Unlock()
+
+	entry, ok := p.backend.Get(key)
+	if !ok {
+		return
+	}
+
+	for _, variant := range entry.Variants {
+		p.backend.Remove(variant)
+	}
+
+	p.backend.Remove(key)
+	p.count(&p.stats.Invalidations)
+}
+
+// count increments the provided counter.
+func (p *Transport) count(counter *uint) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	*counter++
+}
+
+// storable checks if the provided response may be stored,
+// RFC 9111 section 3.
+func storable(entry *Entry) bool {
+	if entry.StatusCode < http.StatusOK || entry.StatusCode == http.StatusPartialContent ||
+		entry.StatusCode == http.StatusNotModified {
+		return false
+	}
+
+	if parseCacheControl(entry.Header).has("no-store") {
+		return false
+	}
+
+	for _, name := range varyNames(entry.Header) {
+		if name == "*" {
+			return false
+		}
+	}
+
+	if _, ok := lifetime(entry); ok {
+		return true
+	}
+
+	return heuristicStatus[entry.StatusCode] && hasValidator(entry.Header)
+}
+
+// varyNames returns the sorted, canonical names listed by the 'Vary' header.
+func varyNames(header http.Header) []string {
+	var names []string
+
+	for _, field := range header.Values("Vary") {
+		for _, name := range strings.Split(field, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// variantKey returns the suffix of the key of the variant
+// selected by the provided request header.
+func variantKey(names []string, header http.Header) string {
+	var key strings.Builder
+
+	for _, name := range names {
+		values := make([]string, 0, len(header.Values(name)))
+
+		for _, value := range header.Values(name) {
+			values = append(values, strings.TrimSpace(value))
+		}
+
+		key.WriteString("\n" + name + ": " + strings.Join(values, ","))
+	}
+
+	return key.String()
+}
+
+// response returns a response serving the provided entry.
+func response(req *http.Request, entry *Entry, currentAge time.Duration) *http.Response {
+	header := entry.Header.Clone()
+	header.Set("Age", strconv.FormatInt(int64(currentAge/time.Second), 10))
+	header.Set(XFromCache, "1")
+
+	return &http.Response{
+		Status:        strconv.Itoa(entry.StatusCode) + " " + http.StatusText(entry.StatusCode),
+		StatusCode:    entry.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
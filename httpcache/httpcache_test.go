@@ -0,0 +1,306 @@
+package httpcache_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/piccobit/generics/httpcache"
+	"github.com/piccobit/generics/lfucache"
+	"github.com/piccobit/generics/lrucache"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func get(client *http.Client, url string, header ...string) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+
+		return
+	}
+
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+
+		return
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("%d %s, from cache: %t\n", resp.StatusCode, body, resp.Header.Get(httpcache.XFromCache) != "")
+}
+
+func ExampleTransport_RoundTrip() {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte("Ferrari"))
+	}))
+	defer server.Close()
+
+	clock := &testClock{now: time.Now()}
+	client := httpcache.New(nil, httpcache.WithClock(clock)).Client()
+
+	get(client, server.URL)
+	get(client, server.URL)
+
+	clock.now = clock.now.Add(2 * time.Minute)
+
+	get(client, server.URL)
+	fmt.Printf("Requests: %d\n", atomic.LoadInt32(&requests))
+	// Output:
+	// 200 Ferrari, from cache: false
+	// 200 Ferrari, from cache: true
+	// 200 Ferrari, from cache: false
+	// Requests: 2
+}
+
+func ExampleTransport_RoundTrip_revalidation() {
+	var requests, notModified int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("ETag", `"v1"`)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("Porsche"))
+	}))
+	defer server.Close()
+
+	transport := httpcache.New(nil)
+	client := transport.Client()
+
+	get(client, server.URL)
+	get(client, server.URL)
+	get(client, server.URL)
+
+	stats := transport.Stats()
+	fmt.Printf("Requests: %d, Not modified: %d\n", atomic.LoadInt32(&requests), atomic.LoadInt32(&notModified))
+	fmt.Printf("Revalidations: %d, Stores: %d\n", stats.Revalidations, stats.Stores)
+	// Output:
+	// 200 Porsche, from cache: false
+	// 200 Porsche, from cache: true
+	// 200 Porsche, from cache: true
+	// Requests: 3, Not modified: 2
+	// Revalidations: 2, Stores: 1
+}
+
+func ExampleTransport_RoundTrip_lastModified() {
+	lastModified := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", lastModified)
+
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+
+			return
+		}
+
+		_, _ = w.Write([]byte("Trabant"))
+	}))
+	defer server.Close()
+
+	clock := &testClock{now: time.Now()}
+	transport := httpcache.New(nil, httpcache.WithClock(clock))
+	client := transport.Client()
+
+	// Without explicit freshness a tenth of the time since the
+	// last modification is used, so 6 minutes here.
+	get(client, server.URL)
+	get(client, server.URL)
+
+	clock.now = clock.now.Add(10 * time.Minute)
+
+	get(client, server.URL)
+	fmt.Printf("Not modified: %d\n", transport.Stats().NotModified)
+	// Output:
+	// 200 Trabant, from cache: false
+	// 200 Trabant, from cache: true
+	// 200 Trabant, from cache: true
+	// Not modified: 1
+}
+
+func ExampleTransport_RoundTrip_vary() {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+
+		if strings.HasPrefix(r.Header.Get("Accept-Language"), "de") {
+			_, _ = w.Write([]byte("Hallo"))
+		} else {
+			_, _ = w.Write([]byte("Hello"))
+		}
+	}))
+	defer server.Close()
+
+	client := httpcache.New(nil).Client()
+
+	get(client, server.URL, "Accept-Language", "en")
+	get(client, server.URL, "Accept-Language", "de")
+	get(client, server.URL, "Accept-Language", "en")
+	get(client, server.URL, "Accept-Language", "de")
+	fmt.Printf("Requests: %d\n", atomic.LoadInt32(&requests))
+	// Output:
+	// 200 Hello, from cache: false
+	// 200 Hallo, from cache: false
+	// 200 Hello, from cache: true
+	// 200 Hallo, from cache: true
+	// Requests: 2
+}
+
+func ExampleTransport_RoundTrip_varyConcurrent() {
+	var vary int32 = 1
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+
+		if atomic.LoadInt32(&vary) == 1 {
+			w.Header().Set("Vary", "Accept-Language")
+		}
+
+		_, _ = w.Write([]byte(r.Header.Get("Accept-Language")))
+	}))
+	defer server.Close()
+
+	backend := lrucache.New[httpcache.Entry](100)
+	client := httpcache.New(nil, httpcache.WithBackend(backend)).Client()
+
+	var wg sync.WaitGroup
+
+	for _, language := range []string{"de", "en", "fr", "it"} {
+		wg.Add(1)
+
+		go func(language string) {
+			defer wg.Done()
+
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			req.Header.Set("Accept-Language", language)
+
+			if resp, err := client.Do(req); err == nil {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}(language)
+	}
+
+	wg.Wait()
+
+	// The marker & all variants are stored.
+	fmt.Printf("Entries: %d\n", len(backend.GetCache()))
+
+	// A response without 'Vary' replaces the marker & its variants.
+	atomic.StoreInt32(&vary, 0)
+	get(client, server.URL, "Accept-Language", "en", "Cache-Control", "no-cache")
+	fmt.Printf("Entries: %d\n", len(backend.GetCache()))
+	// Output:
+	// Entries: 5
+	// 200 en, from cache: false
+	// Entries: 1
+}
+
+func ExampleTransport_RoundTrip_recency() {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer server.Close()
+
+	client := httpcache.New(nil, httpcache.WithBackend(lrucache.New[httpcache.Entry](2))).Client()
+
+	get(client, server.URL+"/a")
+	get(client, server.URL+"/b")
+
+	// The hit makes "/a" the most recently used entry, so "/c" evicts "/b".
+	get(client, server.URL+"/a")
+	get(client, server.URL+"/c")
+	get(client, server.URL+"/a")
+	get(client, server.URL+"/b")
+	// Output:
+	// 200 /a, from cache: false
+	// 200 /b, from cache: false
+	// 200 /a, from cache: true
+	// 200 /c, from cache: false
+	// 200 /a, from cache: true
+	// 200 /b, from cache: false
+}
+
+func ExampleTransport_RoundTrip_noStore() {
+	var requests int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+
+		if r.URL.Path == "/secret" {
+			w.Header().Set("Cache-Control", "no-store")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+
+		_, _ = w.Write([]byte(r.Method))
+	}))
+	defer server.Close()
+
+	// Any backend with the methods of the LRU cache can be used.
+	client := httpcache.New(nil, httpcache.WithBackend(lfucache.New[httpcache.Entry](100))).Client()
+
+	get(client, server.URL+"/secret")
+	get(client, server.URL+"/secret")
+
+	get(client, server.URL+"/cars")
+	get(client, server.URL+"/cars", "Cache-Control", "no-store")
+	get(client, server.URL+"/cars")
+
+	// Successful unsafe requests invalidate the stored responses.
+	resp, err := client.Post(server.URL+"/cars", "text/plain", strings.NewReader("Beetle"))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	} else {
+		resp.Body.Close()
+	}
+
+	get(client, server.URL+"/cars")
+	fmt.Printf("Requests: %d\n", atomic.LoadInt32(&requests))
+	// Output:
+	// 200 GET, from cache: false
+	// 200 GET, from cache: false
+	// 200 GET, from cache: false
+	// 200 GET, from cache: false
+	// 200 GET, from cache: true
+	// 200 GET, from cache: false
+	// Requests: 6
+}
@@ -0,0 +1,145 @@
+package lfucache
+
+import (
+	"time"
+)
+
+// Result describes the outcome of a lookup.
+type Result int
+
+const (
+	// Miss means the cache knows nothing about the ID.
+	Miss Result = iota
+	// Hit means the cache holds a value for the ID.
+	Hit
+	// Absent means the ID is known not to exist.
+	Absent
+)
+
+// String implements the Stringer interface.
+func (r Result) String() string {
+	switch r {
+	case Miss:
+		return "Miss"
+	case Hit:
+		return "Hit"
+	case Absent:
+		return "Absent"
+	default:
+		return "Unknown"
+	}
+}
+
+// Clock provides the current time to the caching of absent IDs.
+// It allows to replace the wall clock, e.g. in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// WithAbsentTTL enables the caching of absent IDs. The 'ttl' parameter
+// defines how long an ID is known to be absent, the 'share' parameter
+// the share of the maximum size of the cache reserved for absent IDs.
+func WithAbsentTTL(ttl time.Duration, share float64) Option {
+	return func(o *options) {
+		o.absentTTL = ttl
+		o.absentShare = share
+	}
+}
+
+// WithClock sets the clock used for the TTL of the absent IDs.
+// The default clock is the wall clock.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+type absentItem struct {
+	id      string
+	expires time.Time
+}
+
+// AddAbsent records that the provided ID is known to be absent.
+// If the space reserved for absent IDs is used up, the oldest one
+// is forgotten. An Overflow error is returned if the caching of
+// absent IDs is not enabled and a Duplicate error if the cache
+// holds a value for the ID.
+func (p *LFUCache[T]) AddAbsent(id string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxAbsent <= 0 {
+		return &OverflowError{}
+	}
+
+	if p.contains(id) {
+		return &DuplicateError{}
+	}
+
+	p.removeAbsent(id)
+
+	if p.absentOrder.Len() >= p.maxAbsent {
+		p.removeAbsent(p.absentOrder.Front().Value.(*absentItem).id)
+	}
+
+	p.absent[id] = p.absentOrder.PushBack(&absentItem{
+		id:      id,
+		expires: p.clock.Now().Add(p.absentTTL),
+	})
+
+	return nil
+}
+
+// AbsentStats returns the number of lookups of IDs known to be
+// absent & the number of IDs currently known to be absent.
+func (p *LFUCache[T]) AbsentStats() (uint, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	now := p.clock.Now()
+
+	for element := p.absentOrder.Front(); element != nil; element = p.absentOrder.Front() {
+		if now.Before(element.Value.(*absentItem).expires) {
+			break
+		}
+
+		p.removeAbsent(element.Value.(*absentItem).id)
+	}
+
+	return p.absentCounter, p.absentOrder.Len()
+}
+
+// isAbsent checks if the provided ID is known to be absent
+// and forgets it if its TTL is expired.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LFUCache[T]) isAbsent(id string) bool {
+	element, ok := p.absent[id]
+	if !ok {
+		return false
+	}
+
+	if p.clock.Now().Before(element.Value.(*absentItem).expires) {
+		return true
+	}
+
+	p.removeAbsent(id)
+
+	return false
+}
+
+// removeAbsent forgets that the provided ID is known to be absent.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LFUCache[T]) removeAbsent(id string) {
+	if element, ok := p.absent[id]; ok {
+		p.absentOrder.Remove(element)
+		delete(p.absent, id)
+	}
+}
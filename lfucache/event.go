@@ -0,0 +1,19 @@
+package lfucache
+
+import (
+	"context"
+
+	"github.com/piccobit/generics/event"
+)
+
+// Subscribe reports the added, evicted and removed entries of the LFU cache
+// until the context is cancelled. See 'event.Broker.Subscribe' for the
+// delivery of the events and the options.
+func (p *LFUCache[T]) Subscribe(ctx context.Context, opts ...event.Option) <-chan event.Event[T] {
+	return p.events.Subscribe(ctx, opts...)
+}
+
+// publish reports the modification of the entry with the provided ID to the subscribers.
+func (p *LFUCache[T]) publish(kind event.Kind, id string, value T) {
+	p.events.Publish(event.Event[T]{Kind: kind, ID: id, Value: value})
+}
@@ -6,12 +6,15 @@ the frequency counters are divided by 2 before a new entry is added.
 package lfucache
 
 import (
+	"container/list"
 	"fmt"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/piccobit/generics/event"
+	"github.com/piccobit/generics/radix"
 )
 
 type item[T any] struct {
@@ -23,9 +26,17 @@ type item[T any] struct {
 type LFUCache[T any] struct {
 	content       map[string]item[T]
 	maxSize       int
+	prefixIndex   *radix.Tree
 	idDropItem    string
 	hitsCounter   uint
 	missedCounter uint
+	absent        map[string]*list.Element
+	absentOrder   *list.List
+	maxAbsent     int
+	absentTTL     time.Duration
+	absentCounter uint
+	clock         Clock
+	events        event.Broker[T]
 	mutex         sync.RWMutex
 }
 
@@ -49,32 +60,88 @@ func (e *DuplicateError) Error() string {
 	return "Duplicate error"
 }
 
+// Option configures optional behaviour of an LFU cache.
+type Option func(*options)
+
+type options struct {
+	absentTTL   time.Duration
+	absentShare float64
+	prefixIndex bool
+	clock       Clock
+}
+
+// WithPrefixIndex maintains a radix tree of the IDs,
+// so that the prefix operations don't scan all entries.
+func WithPrefixIndex() Option {
+	return func(o *options) {
+		o.prefixIndex = true
+	}
+}
+
 // New returns the pointer to a new LFU cache.
 // The 'maxSize' parameter allows to specify a
 // maximum size for the stack.
-func New[T any](maxSize int) *LFUCache[T] {
+// The optional 'opts' parameters allow to enable
+// the caching of absent IDs and the prefix index.
+func New[T any](maxSize int, opts ...Option) *LFUCache[T] {
+	o := options{clock: systemClock{}}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	cache := LFUCache[T]{
-		content: make(map[string]item[T]),
-		maxSize: maxSize,
+		content:     make(map[string]item[T]),
+		maxSize:     maxSize,
+		absent:      make(map[string]*list.Element),
+		absentOrder: list.New(),
+		clock:       o.clock,
+	}
+
+	if o.prefixIndex {
+		cache.prefixIndex = radix.New()
+	}
+
+	if o.absentTTL > 0 && maxSize > 1 {
+		cache.absentTTL = o.absentTTL
+		cache.maxAbsent = int(float64(maxSize) * o.absentShare)
+
+		if cache.maxAbsent < 1 {
+			cache.maxAbsent = 1
+		} else if cache.maxAbsent >= maxSize {
+			cache.maxAbsent = maxSize - 1
+		}
 	}
 
 	return &cache
 }
 
 // Get returns the value stored by the provided ID.
-// If the ID doesn't exist 'false' is returned.
+// If the ID doesn't exist or is known to be absent 'false' is returned.
 func (p *LFUCache[T]) Get(id string) (T, bool) {
-	p.mutex.RLock()
-	defer p.mutex.RUnlock()
+	value, result := p.Lookup(id)
 
-	var ok bool
+	return value, result == Hit
+}
+
+// Lookup returns the value stored by the provided ID and whether
+// the ID was found, is known to be absent or is missing.
+func (p *LFUCache[T]) Lookup(id string) (T, Result) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
 
-	if ok = p.contains(id); !ok {
-		var dummy T
+	var dummy T
+
+	if ok := p.contains(id); !ok {
+		if p.isAbsent(id) {
+			p.absentCounter++
+
+			return dummy, Absent
+		}
 
 		p.missedCounter++
 
-		return dummy, false
+		return dummy, Miss
 	}
 
 	p.hitsCounter++
@@ -85,7 +152,7 @@ func (p *LFUCache[T]) Get(id string) (T, bool) {
 
 	p.content[id] = cacheItem
 
-	return p.content[id].value, true
+	return p.content[id].value, Hit
 }
 
 // Contains checks if the cache contains an element with
@@ -154,7 +221,9 @@ func (p *LFUCache[T]) AddByID(id string, arg T) error {
 	p.reduceFrequency()
 
 	if ok := p.contains(id); !ok {
-		if len(p.content) >= p.maxSize {
+		p.removeAbsent(id)
+
+		if len(p.content) >= p.maxSize-p.maxAbsent {
 			p.dropLFU()
 		}
 
@@ -165,6 +234,8 @@ func (p *LFUCache[T]) AddByID(id string, arg T) error {
 
 		p.content[id] = cacheItem
 		p.idDropItem = id
+		p.index(id)
+		p.publish(event.Added, id, arg)
 	} else {
 		return &DuplicateError{}
 	}
@@ -211,7 +282,11 @@ func (p *LFUCache[T]) dropLFU() {
 		}
 	}
 
-	delete(p.content, p.idDropItem)
+	if cacheItem, ok := p.content[p.idDropItem]; ok {
+		delete(p.content, p.idDropItem)
+		p.unindex(p.idDropItem)
+		p.publish(event.Evicted, p.idDropItem, cacheItem.value)
+	}
 }
 
 // Stats returns the statistics of the provided cache,
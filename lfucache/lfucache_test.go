@@ -1,13 +1,24 @@
 package lfucache_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
+	"testing"
+	"time"
 
 	"github.com/piccobit/generics/lfucache"
 )
 
+type fakeClock struct {
+	now time.Time
+}
+
+func (p *fakeClock) Now() time.Time {
+	return p.now
+}
+
 type car struct {
 	name       string
 	colour     string
@@ -167,3 +178,153 @@ func ExampleLFUCache_AddByID_car() {
 	// {Beetle blue 60}
 	// {Lisbeth silver 42}
 }
+
+func ExampleLFUCache_Subscribe() {
+	var err error
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	myStringLFU := lfucache.New[string](2)
+
+	events := myStringLFU.Subscribe(ctx)
+
+	err = myStringLFU.AddByID("foo", "foo")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringLFU.AddByID("bar", "bar")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, _ = myStringLFU.Get("foo")
+
+	err = myStringLFU.AddByID("baz", "baz")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s: %s\n", e.Kind, e.ID)
+	}
+	// Output:
+	// Added: foo
+	// Added: bar
+	// Evicted: bar
+	// Added: baz
+}
+
+func ExampleLFUCache_Lookup() {
+	var err error
+
+	myStringLFU := lfucache.New[string](10, lfucache.WithAbsentTTL(50*time.Millisecond, 0.2))
+
+	err = myStringLFU.AddByID("foo", "foo")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	// The database doesn't know "bar", remember that for a while.
+	err = myStringLFU.AddAbsent("bar")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for _, id := range []string{"foo", "bar", "baz"} {
+		_, result := myStringLFU.Lookup(id)
+		fmt.Printf("%s: %s\n", id, result)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	_, result := myStringLFU.Lookup("bar")
+	fmt.Printf("bar: %s\n", result)
+
+	hits, misses := myStringLFU.Stats()
+	absentHits, absent := myStringLFU.AbsentStats()
+	fmt.Printf("Hits: %d, Misses: %d, Absent hits: %d, Absent: %d\n", hits, misses, absentHits, absent)
+	// Output:
+	// foo: Hit
+	// bar: Absent
+	// baz: Miss
+	// bar: Miss
+	// Hits: 1, Misses: 2, Absent hits: 1, Absent: 0
+}
+
+func ExampleWithClock() {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	myStringLFU := lfucache.New[string](10, lfucache.WithAbsentTTL(time.Minute, 0.2), lfucache.WithClock(clock))
+
+	err := myStringLFU.AddAbsent("bar")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, result := myStringLFU.Lookup("bar")
+	fmt.Printf("bar: %s\n", result)
+
+	// The TTL expires when the clock passes it.
+	clock.now = clock.now.Add(2 * time.Minute)
+
+	_, result = myStringLFU.Lookup("bar")
+	fmt.Printf("bar: %s\n", result)
+	// Output:
+	// bar: Absent
+	// bar: Miss
+}
+
+func ExampleLFUCache_RemovePrefix() {
+	var err error
+
+	myIntLFU := lfucache.New[int](10, lfucache.WithPrefixIndex())
+
+	for i := 1; i <= 4; i++ {
+		err = myIntLFU.AddByID("tenant:"+strconv.Itoa(i%2)+":user:"+strconv.Itoa(i), i)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Removed: %d\n", myIntLFU.RemovePrefix("tenant:0:"))
+	fmt.Printf("Removed: %v\n", myIntLFU.Remove("tenant:1:user:1"))
+	fmt.Printf("Content: %v\n", myIntLFU.Filter(func(id string, value int) bool {
+		return true
+	}))
+	// Output:
+	// Removed: 2
+	// Removed: true
+	// Content: map[tenant:1:user:3:3]
+}
+
+func benchmarkFilterPrefix(b *testing.B, opts ...lfucache.Option) {
+	const entries = 10000
+
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+
+		cache := lfucache.New[int](entries, opts...)
+
+		for i := 0; i < entries; i++ {
+			_ = cache.AddByID("tenant:"+strconv.Itoa(i%100)+":user:"+strconv.Itoa(i), i)
+		}
+
+		b.StartTimer()
+
+		for i := 0; i < 100; i++ {
+			_ = cache.FilterPrefix("tenant:" + strconv.Itoa(i) + ":")
+		}
+	}
+}
+
+func BenchmarkFilterPrefix(b *testing.B) {
+	b.Run("scan", func(b *testing.B) {
+		benchmarkFilterPrefix(b)
+	})
+	b.Run("radix", func(b *testing.B) {
+		benchmarkFilterPrefix(b, lfucache.WithPrefixIndex())
+	})
+}
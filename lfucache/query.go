@@ -0,0 +1,162 @@
+package lfucache
+
+import (
+	"strings"
+
+	"github.com/piccobit/generics/event"
+)
+
+// Remove removes the entry with the provided ID from the LFU cache.
+// If the ID doesn't exist 'false' is returned.
+func (p *LFUCache[T]) Remove(id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !p.contains(id) {
+		return false
+	}
+
+	p.remove(id)
+
+	return true
+}
+
+// RemoveFunc removes all entries for which the provided function returns
+// 'true' and returns the number of removed entries.
+// The function must not use the cache.
+func (p *LFUCache[T]) RemoveFunc(fn func(id string, value T) bool) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var removed []string
+
+	for id, cacheItem := range p.content {
+		if fn(id, cacheItem.value) {
+			removed = append(removed, id)
+		}
+	}
+
+	for _, id := range removed {
+		p.remove(id)
+	}
+
+	return len(removed)
+}
+
+// RemovePrefix removes all entries whose ID starts with the provided
+// prefix and returns the number of removed entries.
+func (p *LFUCache[T]) RemovePrefix(prefix string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var removed []string
+
+	p.walkPrefix(prefix, func(id string) {
+		removed = append(removed, id)
+	})
+
+	for _, id := range removed {
+		p.remove(id)
+	}
+
+	return len(removed)
+}
+
+// Filter returns the entries for which the provided function returns 'true'.
+// The function must not use the cache.
+func (p *LFUCache[T]) Filter(fn func(id string, value T) bool) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for id, cacheItem := range p.content {
+		if fn(id, cacheItem.value) {
+			content[id] = cacheItem.value
+		}
+	}
+
+	return content
+}
+
+// FilterPrefix returns the entries whose ID starts with the provided prefix.
+func (p *LFUCache[T]) FilterPrefix(prefix string) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	p.walkPrefix(prefix, func(id string) {
+		content[id] = p.content[id].value
+	})
+
+	return content
+}
+
+// remove removes the entry with the provided ID. If the entry was the next
+// one to be dropped, the least frequently used of the remaining entries
+// takes its place.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LFUCache[T]) remove(id string) {
+	cacheItem := p.content[id]
+
+	delete(p.content, id)
+	p.unindex(id)
+	p.publish(event.Removed, id, cacheItem.value)
+
+	if id != p.idDropItem {
+		return
+	}
+
+	p.idDropItem = ""
+
+	for otherID, otherItem := range p.content {
+		dropItem, ok := p.content[p.idDropItem]
+
+		if !ok || otherItem.freq < dropItem.freq ||
+			(otherItem.freq == dropItem.freq && otherItem.added.Before(dropItem.added)) {
+			p.idDropItem = otherID
+		}
+	}
+}
+
+// walkPrefix calls the provided function for all IDs with the provided prefix,
+// using the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *LFUCache[T]) walkPrefix(prefix string, fn func(id string)) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.WalkPrefix(prefix, func(id string) bool {
+			fn(id)
+
+			return true
+		})
+
+		return
+	}
+
+	for id := range p.content {
+		if strings.HasPrefix(id, prefix) {
+			fn(id)
+		}
+	}
+}
+
+// index adds the provided ID to the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LFUCache[T]) index(id string) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.Insert(id)
+	}
+}
+
+// unindex removes the provided ID from the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LFUCache[T]) unindex(id string) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.Delete(id)
+	}
+}
@@ -0,0 +1,387 @@
+/*
+Package loading implements a loading cache on top of the caches of this module.
+Missing values are loaded using a loader function. After a soft TTL a value is
+stale: it is still returned, but a single refresh is started in the background,
+so hot keys don't wait for the reload. After a hard TTL the value is no longer
+returned and it is loaded again synchronously.
+*/
+package loading
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Loader loads the value with the provided ID.
+type Loader[T any] func(ctx context.Context, id string) (T, error)
+
+// Entry is a cached value together with the time it was loaded.
+type Entry[T any] struct {
+	Value  T
+	Loaded time.Time
+}
+
+// Backend stores the entries of a loading cache.
+type Backend[T any] interface {
+	Load(id string) (Entry[T], bool)
+	Save(id string, entry Entry[T]) error
+}
+
+// Clock provides the current time to the loading cache.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// Stats holds the statistics of a loading cache.
+type Stats struct {
+	// Hits counts the fresh values returned.
+	Hits uint
+	// StaleHits counts the stale values returned.
+	StaleHits uint
+	// Misses counts the missing or expired values.
+	Misses uint
+	// Loads & LoadErrors count the synchronous loads.
+	Loads      uint
+	LoadErrors uint
+	// Refreshes & RefreshErrors count the background refreshes.
+	Refreshes     uint
+	RefreshErrors uint
+	// RefreshesSkipped counts the refreshes not started because of
+	// the concurrency limit or the backoff after an error.
+	RefreshesSkipped uint
+}
+
+// Option configures optional behaviour of a loading cache.
+type Option func(*options)
+
+type options struct {
+	softTTL    time.Duration
+	hardTTL    time.Duration
+	maxRefresh int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	clock      Clock
+}
+
+// WithSoftTTL sets the age after which values are stale and refreshed in the
+// background. By default values are never refreshed in the background.
+func WithSoftTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.softTTL = ttl
+	}
+}
+
+// WithHardTTL sets the age after which values are no longer returned.
+// By default values never expire.
+func WithHardTTL(ttl time.Duration) Option {
+	return func(o *options) {
+		o.hardTTL = ttl
+	}
+}
+
+// WithMaxRefreshes sets the maximum number of concurrently running
+// background refreshes. The default is 4.
+func WithMaxRefreshes(maxRefreshes int) Option {
+	return func(o *options) {
+		o.maxRefresh = maxRefreshes
+	}
+}
+
+// WithBackoff sets the delay before a failed refresh is retried. The delay
+// starts at 'minBackoff' and doubles with every further error up to 'maxBackoff'.
+// The defaults are 1 second and 1 minute.
+func WithBackoff(minBackoff time.Duration, maxBackoff time.Duration) Option {
+	return func(o *options) {
+		o.minBackoff = minBackoff
+		o.maxBackoff = maxBackoff
+	}
+}
+
+// WithClock sets the clock used to determine the age of the values.
+func WithClock(clock Clock) Option {
+	return func(o *options) {
+		o.clock = clock
+	}
+}
+
+type call[T any] struct {
+	done    chan struct{}
+	value   T
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+type backoff struct {
+	errors int
+	retry  time.Time
+}
+
+type Cache[T any] struct {
+	backend    Backend[T]
+	loader     Loader[T]
+	options    options
+	slots      chan struct{}
+	calls      map[string]*call[T]
+	refreshing map[string]struct{}
+	failures   map[string]backoff
+	pruned     time.Time
+	stats      Stats
+	running    sync.WaitGroup
+	mutex      sync.Mutex
+}
+
+// New returns the pointer to a new loading cache storing its entries in
+// the provided backend and loading missing values using the provided loader.
+// The optional 'opts' parameters allow to set the TTLs and to configure
+// the background refreshes.
+func New[T any](backend Backend[T], loader Loader[T], opts ...Option) *Cache[T] {
+	o := options{
+		maxRefresh: 4,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		clock:      systemClock{},
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.maxRefresh < 1 {
+		o.maxRefresh = 1
+	}
+
+	cache := Cache[T]{
+		backend:    backend,
+		loader:     loader,
+		options:    o,
+		slots:      make(chan struct{}, o.maxRefresh),
+		calls:      make(map[string]*call[T]),
+		refreshing: make(map[string]struct{}),
+		failures:   make(map[string]backoff),
+	}
+
+	return &cache
+}
+
+// Get returns the value with the provided ID. A missing or expired value is
+// loaded and stored before it is returned, concurrent calls for the same ID
+// share a single load. The shared load doesn't use the context of any caller,
+// a caller whose context is cancelled stops waiting, and the load is only
+// cancelled when all callers stopped waiting. A stale value is returned
+// immediately and refreshed in the background.
+// If storing the loaded value fails, the value is returned nevertheless.
+func (p *Cache[T]) Get(ctx context.Context, id string) (T, error) {
+	now := p.options.clock.Now()
+
+	if entry, ok := p.backend.Load(id); ok {
+		age := now.Sub(entry.Loaded)
+
+		if p.options.hardTTL <= 0 || age < p.options.hardTTL {
+			p.mutex.Lock()
+			defer p.mutex.Unlock()
+
+			if p.options.softTTL <= 0 || age < p.options.softTTL {
+				p.stats.Hits++
+			} else {
+				p.stats.StaleHits++
+				p.refresh(id, now)
+			}
+
+			return entry.Value, nil
+		}
+	}
+
+	return p.load(ctx, id)
+}
+
+// Stats returns the statistics of the loading cache.
+func (p *Cache[T]) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.stats
+}
+
+// Wait waits until all running background refreshes are finished.
+func (p *Cache[T]) Wait() {
+	p.running.Wait()
+}
+
+// load loads the value with the provided ID synchronously
+// or waits for the load already running.
+func (p *Cache[T]) load(ctx context.Context, id string) (T, error) {
+	p.mutex.Lock()
+
+	p.stats.Misses++
+
+	c, ok := p.calls[id]
+	if !ok {
+		var loadCtx context.Context
+
+		c = &call[T]{done: make(chan struct{})}
+		loadCtx, c.cancel = context.WithCancel(context.Background())
+		p.calls[id] = c
+		p.stats.Loads++
+
+		go p.run(loadCtx, id, c)
+	}
+
+	c.waiters++
+
+	p.mutex.Unlock()
+
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		p.mutex.Lock()
+
+		c.waiters--
+
+		// Nobody waits for the load anymore, a following call starts a new one.
+		if c.waiters == 0 {
+			c.cancel()
+
+			if p.calls[id] == c {
+				delete(p.calls, id)
+			}
+		}
+
+		p.mutex.Unlock()
+
+		var dummy T
+
+		return dummy, ctx.Err()
+	}
+}
+
+// run runs the shared load of the provided call and stores the loaded value.
+func (p *Cache[T]) run(ctx context.Context, id string, c *call[T]) {
+	defer c.cancel()
+
+	c.value, c.err = p.loader(ctx, id)
+	if c.err == nil {
+		_ = p.backend.Save(id, Entry[T]{Value: c.value, Loaded: p.options.clock.Now()})
+	}
+
+	p.mutex.Lock()
+
+	if p.calls[id] == c {
+		delete(p.calls, id)
+	}
+
+	if c.err != nil {
+		p.stats.LoadErrors++
+	} else {
+		delete(p.failures, id)
+	}
+
+	p.mutex.Unlock()
+
+	close(c.done)
+}
+
+// refresh starts a background refresh of the value with the provided ID,
+// unless a refresh of the value is already running, the concurrency limit
+// is reached or the last refresh failed recently.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[T]) refresh(id string, now time.Time) {
+	if _, ok := p.refreshing[id]; ok {
+		return
+	}
+
+	if failure, ok := p.failures[id]; ok && now.Before(failure.retry) {
+		p.stats.RefreshesSkipped++
+
+		return
+	}
+
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		p.stats.RefreshesSkipped++
+
+		return
+	}
+
+	p.refreshing[id] = struct{}{}
+	p.stats.Refreshes++
+	p.running.Add(1)
+
+	go func() {
+		defer p.running.Done()
+
+		value, err := p.loader(context.Background(), id)
+		if err == nil {
+			_ = p.backend.Save(id, Entry[T]{Value: value, Loaded: p.options.clock.Now()})
+		}
+
+		p.mutex.Lock()
+
+		delete(p.refreshing, id)
+
+		if err != nil {
+			p.stats.RefreshErrors++
+
+			now := p.options.clock.Now()
+
+			p.prune(now)
+
+			failure := p.failures[id]
+			failure.errors++
+			failure.retry = now.Add(p.backoff(failure.errors))
+			p.failures[id] = failure
+		} else {
+			delete(p.failures, id)
+		}
+
+		p.mutex.Unlock()
+
+		<-p.slots
+	}()
+}
+
+// prune removes the failures whose retry is due for longer than the maximum
+// backoff, so IDs which aren't requested anymore don't stay in the map.
+// It only scans the failures once per maximum backoff.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[T]) prune(now time.Time) {
+	if now.Sub(p.pruned) < p.options.maxBackoff {
+		return
+	}
+
+	p.pruned = now
+
+	for id, failure := range p.failures {
+		if now.Sub(failure.retry) > p.options.maxBackoff {
+			delete(p.failures, id)
+		}
+	}
+}
+
+// backoff returns the delay after the provided number of consecutive errors.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Cache[T]) backoff(errors int) time.Duration {
+	delay := p.options.minBackoff
+
+	for i := 1; i < errors && delay < p.options.maxBackoff; i++ {
+		delay *= 2
+	}
+
+	if delay > p.options.maxBackoff {
+		delay = p.options.maxBackoff
+	}
+
+	return delay
+}
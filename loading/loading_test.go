@@ -0,0 +1,173 @@
+package loading_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/piccobit/generics/cache"
+	"github.com/piccobit/generics/loading"
+	"github.com/piccobit/generics/lrucache"
+)
+
+type fakeClock struct {
+	now   time.Time
+	mutex sync.Mutex
+}
+
+func (p *fakeClock) Now() time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.now
+}
+
+func (p *fakeClock) Advance(d time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.now = p.now.Add(d)
+}
+
+func ExampleWithSoftTTL() {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	version := 0
+
+	myIntCache := lrucache.NewLoading(10, func(ctx context.Context, id string) (int, error) {
+		version++
+
+		return version, nil
+	}, loading.WithSoftTTL(time.Minute), loading.WithHardTTL(time.Hour), loading.WithClock(clock))
+
+	ctx := context.Background()
+
+	value, err := myIntCache.Get(ctx, "foo")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Fresh: %d\n", value)
+
+	// After the soft TTL the stale value is returned and refreshed in the background.
+	clock.Advance(2 * time.Minute)
+
+	value, _ = myIntCache.Get(ctx, "foo")
+	fmt.Printf("Stale: %d\n", value)
+
+	myIntCache.Wait()
+
+	value, _ = myIntCache.Get(ctx, "foo")
+	fmt.Printf("Refreshed: %d\n", value)
+
+	// After the hard TTL the value is loaded synchronously.
+	clock.Advance(2 * time.Hour)
+
+	value, _ = myIntCache.Get(ctx, "foo")
+	fmt.Printf("Reloaded: %d\n", value)
+
+	fmt.Printf("Stats: %+v\n", myIntCache.Stats())
+	// Output:
+	// Fresh: 1
+	// Stale: 1
+	// Refreshed: 2
+	// Reloaded: 3
+	// Stats: {Hits:1 StaleHits:1 Misses:2 Loads:2 LoadErrors:0 Refreshes:1 RefreshErrors:0 RefreshesSkipped:0}
+}
+
+func ExampleWithBackoff() {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	failing := false
+
+	myStringCache := cache.NewLoading(0, func(ctx context.Context, id string) (string, error) {
+		if failing {
+			return "", errors.New("backend unavailable")
+		}
+
+		return "value of " + id, nil
+	}, loading.WithSoftTTL(time.Minute), loading.WithBackoff(time.Minute, time.Hour), loading.WithClock(clock))
+
+	ctx := context.Background()
+
+	_, err := myStringCache.Get(ctx, "foo")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	failing = true
+
+	clock.Advance(2 * time.Minute)
+
+	// The failing refresh keeps the stale value & isn't retried until the backoff passed.
+	for i := 0; i < 3; i++ {
+		value, _ := myStringCache.Get(ctx, "foo")
+		myStringCache.Wait()
+
+		fmt.Println(value)
+	}
+
+	stats := myStringCache.Stats()
+	fmt.Printf("Stale hits: %d, refreshes: %d, errors: %d, skipped: %d\n",
+		stats.StaleHits, stats.Refreshes, stats.RefreshErrors, stats.RefreshesSkipped)
+	// Output:
+	// value of foo
+	// value of foo
+	// value of foo
+	// Stale hits: 3, refreshes: 1, errors: 1, skipped: 2
+}
+
+func ExampleCache_Get_cancel() {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	myStringCache := cache.NewLoading(0, func(ctx context.Context, id string) (string, error) {
+		close(started)
+		<-release
+
+		return "value of " + id, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancelled := make(chan error)
+
+	go func() {
+		_, err := myStringCache.Get(ctx, "foo")
+		cancelled <- err
+	}()
+
+	<-started
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		value, err := myStringCache.Get(context.Background(), "foo")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Second caller: %s\n", value)
+	}()
+
+	for myStringCache.Stats().Misses < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The first caller gives up, the shared load continues for the second one.
+	cancel()
+	fmt.Printf("First caller: %v\n", <-cancelled)
+
+	close(release)
+	wg.Wait()
+
+	fmt.Printf("Loads: %d\n", myStringCache.Stats().Loads)
+	// Output:
+	// First caller: context canceled
+	// Second caller: value of foo
+	// Loads: 1
+}
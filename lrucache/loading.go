@@ -0,0 +1,26 @@
+package lrucache
+
+import (
+	"github.com/piccobit/generics/loading"
+)
+
+// NewLoading returns the pointer to a new loading cache, which stores its
+// entries in a new LRU cache with the provided maximum size.
+// Missing values are loaded using the provided loader, the optional 'opts'
+// parameters allow to set the TTLs and to configure the background refreshes.
+func NewLoading[T any](maxSize int, loader loading.Loader[T], opts ...loading.Option) *loading.Cache[T] {
+	return loading.New[T](&loadingBackend[T]{cache: New[loading.Entry[T]](maxSize)}, loader, opts...)
+}
+
+// loadingBackend adapts the LRU cache to the backend of a loading cache.
+type loadingBackend[T any] struct {
+	cache *LRUCache[loading.Entry[T]]
+}
+
+func (p *loadingBackend[T]) Load(id string) (loading.Entry[T], bool) {
+	return p.cache.Get(id)
+}
+
+func (p *loadingBackend[T]) Save(id string, entry loading.Entry[T]) error {
+	return p.cache.AddByID(id, entry)
+}
@@ -5,21 +5,36 @@ package lrucache
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/piccobit/generics/event"
+	"github.com/piccobit/generics/radix"
 )
 
 type item[T any] struct {
 	id    string
 	value T
+	seq   uint64
 }
 
 type LRUCache[T any] struct {
-	content []item[T]
-	maxSize int
-	mutex   sync.RWMutex
+	content     []item[T]
+	maxSize     int
+	prefixIndex *radix.Tree
+	// seq is the sequence number of the last added or moved item, the
+	// items are sorted by it. With the prefix index, positions maps the
+	// IDs to the sequence numbers of their items.
+	seq       uint64
+	positions map[string]uint64
+	onEvict   func(id string, value T)
+	tags      map[string]map[string]struct{}
+	itemTags  map[string][]string
+	events    event.Broker[T]
+	observers []Observer
+	mutex     sync.RWMutex
 }
 
 type UnderflowError struct{}
@@ -37,21 +52,62 @@ func (e *OverflowError) Error() string {
 	return "Overflow error"
 }
 
+// Option configures optional behaviour of an LRU cache.
+type Option func(*options)
+
+type options struct {
+	prefixIndex bool
+}
+
+// WithPrefixIndex maintains a radix tree of the IDs and the positions of
+// their entries, so that the prefix operations and the lookups by ID
+// don't compare all IDs.
+func WithPrefixIndex() Option {
+	return func(o *options) {
+		o.prefixIndex = true
+	}
+}
+
 // New returns the pointer to a new LRU cache.
 // The 'maxSize' parameter allows to specify a
 // maximum size for the stack.
-func New[T any](maxSize int) *LRUCache[T] {
+// The optional 'opts' parameters allow to enable
+// the prefix index.
+func New[T any](maxSize int, opts ...Option) *LRUCache[T] {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	cache := LRUCache[T]{maxSize: maxSize}
 
+	if o.prefixIndex {
+		cache.prefixIndex = radix.New()
+		cache.positions = make(map[string]uint64)
+	}
+
 	return &cache
 }
 
+// SetEvictionCallback sets a function which is called synchronously
+// with every entry evicted to make place for a new one.
+// The function must not use the cache.
+func (p *LRUCache[T]) SetEvictionCallback(fn func(id string, value T)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.onEvict = fn
+}
+
 // Get returns the value stored by the provided ID.
 // If the ID doesn't exist 'false' is returned.
 func (p *LRUCache[T]) Get(id string) (T, bool) {
 	p.mutex.RLock()
 	defer p.mutex.RUnlock()
 
+	p.observe(id)
+
 	var idx int
 	var ok bool
 
@@ -80,10 +136,22 @@ func (p *LRUCache[T]) Contains(id string) (int, bool) {
 }
 
 // contains checks if the cache contains an element with
-// the provided ID.
+// the provided ID. With the prefix index the position is
+// searched by the sequence number of the element.
 // This function is only used internally and does not use
 // the mutex to lock during the read access.
 func (p *LRUCache[T]) contains(id string) (int, bool) {
+	if p.positions != nil {
+		seq, ok := p.positions[id]
+		if !ok {
+			return -1, false
+		}
+
+		return sort.Search(len(p.content), func(i int) bool {
+			return p.content[i].seq >= seq
+		}), true
+	}
+
 	for idx, i := range p.content {
 		if i.id == id {
 			return idx, true
@@ -93,6 +161,19 @@ func (p *LRUCache[T]) contains(id string) (int, bool) {
 	return -1, false
 }
 
+// stamp returns the next sequence number for the item with the provided ID.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) stamp(id string) uint64 {
+	p.seq++
+
+	if p.positions != nil {
+		p.positions[id] = p.seq
+	}
+
+	return p.seq
+}
+
 // String implements the Stringer interface.
 func (p *LRUCache[T]) String() string {
 	p.mutex.RLock()
@@ -119,37 +200,58 @@ func (p *LRUCache[T]) String() string {
 // AddByID adds the provided argument with the provided ID to the LRU cache.
 // If the added item is a new one and the LRU cache has already reached its
 // maximum size, the oldest item is dropped to make place for the new one.
-// If the added item is already part of the LRU cache it will be moved to the
-// end of the cache.
+// If the added item is already part of the LRU cache its value is updated and
+// it will be moved to the end of the cache.
 func (p *LRUCache[T]) AddByID(id string, arg T) error {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
+	p.add(id, arg)
+
+	return nil
+}
+
+// add adds the provided argument with the provided ID to the LRU cache.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) add(id string, arg T) {
 	if idx, ok := p.contains(id); ok {
 		valueAtIndex := p.content[idx]
+		valueAtIndex.value = arg
+		valueAtIndex.seq = p.stamp(id)
 		before := p.content[:idx]
 		after := p.content[idx+1:]
 		newContent := append(before, after...)
 		p.content = append(newContent, valueAtIndex)
+		p.publish(event.Updated, valueAtIndex.id, valueAtIndex.value)
 	} else {
 		if len(p.content) < p.maxSize {
-			p.content = append(p.content, item[T]{id, arg})
+			p.content = append(p.content, item[T]{id, arg, p.stamp(id)})
 		} else {
+			evicted := p.content[0]
 			newContent := p.content[1:]
-			newContent = append(newContent, item[T]{id, arg})
+			newContent = append(newContent, item[T]{id, arg, p.stamp(id)})
 			p.content = newContent
+			p.untag(evicted.id)
+			p.unindex(evicted.id)
+			p.publish(event.Evicted, evicted.id, evicted.value)
+
+			if p.onEvict != nil {
+				p.onEvict(evicted.id, evicted.value)
+			}
 		}
-	}
 
-	return nil
+		p.index(id)
+		p.publish(event.Added, id, arg)
+	}
 }
 
 // Add adds the provided argument to the LRU cache.
 // The ID used is either provided using the ID interface or generated internally.
 // If the added item is a new one and the LRU cache has already reached its
 // maximum size, the oldest item is dropped to make place for the new one.
-// If the added item is already part of the LRU cache it will be moved to the
-// end of the cache.
+// If the added item is already part of the LRU cache its value is updated and
+// it will be moved to the end of the cache.
 func (p *LRUCache[T]) Add(arg T) (string, error) {
 	var id string
 
@@ -172,3 +274,18 @@ func (p *LRUCache[T]) GetCache() map[string]T {
 
 	return content
 }
+
+// GetIDs returns the IDs of the cache content ordered
+// from the least to the most recently used entry.
+func (p *LRUCache[T]) GetIDs() []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	ids := make([]string, len(p.content))
+
+	for i, cacheItem := range p.content {
+		ids[i] = cacheItem.id
+	}
+
+	return ids
+}
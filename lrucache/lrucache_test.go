@@ -1,6 +1,7 @@
 package lrucache_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strconv"
@@ -200,11 +201,42 @@ func ExampleLRUCache_AddByID_car_2() {
 	for k, v := range myCarLRU.GetCache() {
 		fmt.Printf("%s: %v\n", k, v)
 	}
-	// Output:
+	// Unordered output:
 	// Corvette: {Little red 200}
 	// VW: {Beetle blue 60}
 }
 
+func ExampleLRUCache_AddByID_update() {
+	var err error
+
+	myStringLRU := lrucache.New[string](2)
+
+	for _, id := range []string{"foo", "bar"} {
+		err = myStringLRU.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	// Adding an existing ID updates its value and makes it the newest item.
+	err = myStringLRU.AddByID("foo", "FOO")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringLRU.AddByID("baz", "baz")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myStringLRU.Get("foo")
+	fmt.Printf("foo: %s %v\n", value, ok)
+	fmt.Printf("Content: %v\n", myStringLRU)
+	// Output:
+	// foo: FOO true
+	// Content: [FOO,baz]
+}
+
 func ExampleLRUCache_Get_car() {
 	var err error
 
@@ -246,3 +278,118 @@ func ExampleLRUCache_Get_car() {
 	// Corvette: {Little red 200}
 	// VW: {Beetle blue 60}
 }
+
+func ExampleLRUCache_Subscribe() {
+	var err error
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	myStringLRU := lrucache.New[string](2)
+
+	events := myStringLRU.Subscribe(ctx)
+
+	for _, id := range []string{"foo", "bar", "foo", "baz"} {
+		err = myStringLRU.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s: %s\n", e.Kind, e.ID)
+	}
+	// Output:
+	// Added: foo
+	// Added: bar
+	// Updated: foo
+	// Evicted: bar
+	// Added: baz
+}
+
+func ExampleLRUCache_InvalidateTag() {
+	var err error
+
+	myStringLRU := lrucache.New[string](3)
+
+	for _, id := range []string{"a", "b", "c"} {
+		err = myStringLRU.AddWithTags(id, id, "letters", "object-"+id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	// Evicting "a" removes it from the tag index.
+	err = myStringLRU.AddWithTags("1", "1", "digits")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Tags of a: %v\n", myStringLRU.Tags("a"))
+	fmt.Printf("Invalidated: %d\n", myStringLRU.InvalidateTag("letters"))
+	fmt.Printf("Content: %v\n", myStringLRU)
+	fmt.Printf("Invalidated: %d\n", myStringLRU.InvalidateTag("object-b"))
+	// Output:
+	// Tags of a: []
+	// Invalidated: 2
+	// Content: [1]
+	// Invalidated: 0
+}
+
+func ExampleLRUCache_RemoveFunc() {
+	var err error
+
+	myIntLRU := lrucache.New[int](10)
+
+	for i := 1; i <= 6; i++ {
+		err = myIntLRU.AddByID("tenant:"+strconv.Itoa(i%2)+":user:"+strconv.Itoa(i), i)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	removed := myIntLRU.RemoveFunc(func(id string, value int) bool {
+		return value > 4
+	})
+
+	fmt.Printf("Removed: %d\n", removed)
+	fmt.Printf("Tenant 1: %v\n", myIntLRU.FilterPrefix("tenant:1:"))
+	fmt.Printf("Removed: %d\n", myIntLRU.RemovePrefix("tenant:0:"))
+	fmt.Printf("Content: %v\n", myIntLRU)
+	// Output:
+	// Removed: 2
+	// Tenant 1: map[tenant:1:user:1:1 tenant:1:user:3:3]
+	// Removed: 2
+	// Content: [1,3]
+}
+
+func ExampleWithPrefixIndex() {
+	var err error
+
+	myIntLRU := lrucache.New[int](4, lrucache.WithPrefixIndex())
+
+	for i := 1; i <= 5; i++ {
+		err = myIntLRU.AddByID("tenant:"+strconv.Itoa(i%2)+":user:"+strconv.Itoa(i), i)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	// Moves & evictions keep the index of the positions up to date.
+	err = myIntLRU.AddByID("tenant:0:user:2", 20)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myIntLRU.Get("tenant:1:user:1")
+	fmt.Printf("User 1: %d %v\n", value, ok)
+	fmt.Printf("Tenant 1: %v\n", myIntLRU.FilterPrefix("tenant:1:"))
+	fmt.Printf("Removed: %d\n", myIntLRU.RemovePrefix("tenant:1:"))
+	fmt.Printf("Content: %v\n", myIntLRU)
+	// Output:
+	// User 1: 0 false
+	// Tenant 1: map[tenant:1:user:3:3 tenant:1:user:5:5]
+	// Removed: 2
+	// Content: [4,20]
+}
@@ -0,0 +1,26 @@
+package lrucache
+
+// Observer is notified about every lookup of the cache,
+// e.g. to analyse the access stream of the cache.
+type Observer interface {
+	Observe(id string)
+}
+
+// AddObserver registers the provided observer, which is called
+// with the ID of every lookup made by 'Get' after the registration.
+// Observers are called synchronously and must not use the cache.
+func (p *LRUCache[T]) AddObserver(observer Observer) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.observers = append(p.observers, observer)
+}
+
+// observe reports the lookup of the provided ID to the observers.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *LRUCache[T]) observe(id string) {
+	for _, observer := range p.observers {
+		observer.Observe(id)
+	}
+}
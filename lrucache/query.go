@@ -0,0 +1,174 @@
+package lrucache
+
+import (
+	"strings"
+
+	"github.com/piccobit/generics/event"
+)
+
+// Remove removes the entry with the provided ID from the LRU cache.
+// If the ID doesn't exist 'false' is returned.
+func (p *LRUCache[T]) Remove(id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.contains(id); !ok {
+		return false
+	}
+
+	p.removeIDs(map[string]struct{}{id: {}})
+
+	return true
+}
+
+// RemoveFunc removes all entries for which the provided function returns
+// 'true' and returns the number of removed entries.
+// The function must not use the cache.
+func (p *LRUCache[T]) RemoveFunc(fn func(id string, value T) bool) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	removed := make(map[string]struct{})
+
+	for _, cacheItem := range p.content {
+		if fn(cacheItem.id, cacheItem.value) {
+			removed[cacheItem.id] = struct{}{}
+		}
+	}
+
+	p.removeIDs(removed)
+
+	return len(removed)
+}
+
+// RemovePrefix removes all entries whose ID starts with the provided
+// prefix and returns the number of removed entries.
+func (p *LRUCache[T]) RemovePrefix(prefix string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	removed := make(map[string]struct{})
+
+	p.walkPrefix(prefix, func(idx int) {
+		removed[p.content[idx].id] = struct{}{}
+	})
+
+	p.removeIDs(removed)
+
+	return len(removed)
+}
+
+// Filter returns the entries for which the provided function returns 'true'.
+// The function must not use the cache.
+func (p *LRUCache[T]) Filter(fn func(id string, value T) bool) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for _, cacheItem := range p.content {
+		if fn(cacheItem.id, cacheItem.value) {
+			content[cacheItem.id] = cacheItem.value
+		}
+	}
+
+	return content
+}
+
+// FilterPrefix returns the entries whose ID starts with the provided prefix.
+func (p *LRUCache[T]) FilterPrefix(prefix string) map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	p.walkPrefix(prefix, func(idx int) {
+		content[p.content[idx].id] = p.content[idx].value
+	})
+
+	return content
+}
+
+// removeIDs removes the entries with the provided IDs and their tags.
+// With the prefix index only the entries following the first removed
+// one are moved, otherwise all entries are compared.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) removeIDs(ids map[string]struct{}) {
+	first := 0
+
+	if p.positions != nil {
+		first = len(p.content)
+
+		for id := range ids {
+			if idx, ok := p.contains(id); ok && idx < first {
+				first = idx
+			}
+		}
+	}
+
+	content := p.content[:first]
+
+	for _, cacheItem := range p.content[first:] {
+		if _, ok := ids[cacheItem.id]; !ok {
+			content = append(content, cacheItem)
+
+			continue
+		}
+
+		p.untag(cacheItem.id)
+		p.unindex(cacheItem.id)
+		p.publish(event.Removed, cacheItem.id, cacheItem.value)
+	}
+
+	var dummy item[T]
+
+	for i := len(content); i < len(p.content); i++ {
+		p.content[i] = dummy
+	}
+
+	p.content = content
+}
+
+// walkPrefix calls the provided function with the positions of all entries
+// whose ID has the provided prefix, using the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *LRUCache[T]) walkPrefix(prefix string, fn func(idx int)) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.WalkPrefix(prefix, func(id string) bool {
+			if idx, ok := p.contains(id); ok {
+				fn(idx)
+			}
+
+			return true
+		})
+
+		return
+	}
+
+	for idx, cacheItem := range p.content {
+		if strings.HasPrefix(cacheItem.id, prefix) {
+			fn(idx)
+		}
+	}
+}
+
+// index adds the provided ID to the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) index(id string) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.Insert(id)
+	}
+}
+
+// unindex removes the provided ID from the prefix index if it is enabled.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) unindex(id string) {
+	if p.prefixIndex != nil {
+		p.prefixIndex.Delete(id)
+		delete(p.positions, id)
+	}
+}
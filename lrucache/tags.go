@@ -0,0 +1,90 @@
+package lrucache
+
+// AddWithTags adds the provided argument with the provided ID to the LRU cache,
+// like 'AddByID' does, and attaches the provided tags to it, replacing the tags
+// attached before. All entries with a tag can be removed using 'InvalidateTag'.
+func (p *LRUCache[T]) AddWithTags(id string, arg T, tags ...string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.add(id, arg)
+	p.untag(id)
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if p.tags == nil {
+		p.tags = make(map[string]map[string]struct{})
+		p.itemTags = make(map[string][]string)
+	}
+
+	for _, tag := range tags {
+		ids, ok := p.tags[tag]
+		if !ok {
+			ids = make(map[string]struct{})
+			p.tags[tag] = ids
+		}
+
+		if _, ok = ids[id]; ok {
+			continue
+		}
+
+		ids[id] = struct{}{}
+		p.itemTags[id] = append(p.itemTags[id], tag)
+	}
+
+	return nil
+}
+
+// InvalidateTag removes all entries with the provided tag from the LRU cache
+// and returns the number of removed entries.
+func (p *LRUCache[T]) InvalidateTag(tag string) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ids := p.tags[tag]
+	if len(ids) == 0 {
+		return 0
+	}
+
+	removed := make(map[string]struct{}, len(ids))
+
+	for id := range ids {
+		removed[id] = struct{}{}
+	}
+
+	p.removeIDs(removed)
+
+	return len(removed)
+}
+
+// Tags returns the tags attached to the entry with the provided ID.
+func (p *LRUCache[T]) Tags(id string) []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return append([]string(nil), p.itemTags[id]...)
+}
+
+// untag removes all tags of the entry with the provided ID from the tag index.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *LRUCache[T]) untag(id string) {
+	tags, ok := p.itemTags[id]
+	if !ok {
+		return
+	}
+
+	for _, tag := range tags {
+		ids := p.tags[tag]
+
+		delete(ids, id)
+
+		if len(ids) == 0 {
+			delete(p.tags, tag)
+		}
+	}
+
+	delete(p.itemTags, id)
+}
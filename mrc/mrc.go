@@ -0,0 +1,368 @@
+/*
+Package mrc estimates the miss ratio curve of an LRU cache, the miss ratio as function of
+the cache size, from the observed access stream using SHARDS (Spatially Hashed Approximate
+Reuse Distance Sampling). Only the accesses of keys whose hash is below a threshold are
+sampled, for those the reuse distance, the number of distinct keys accessed since the last
+access of the same key, is measured and scaled by the sampling rate.
+
+The number of tracked keys is bounded. If the bound is exceeded, the threshold is lowered
+and the keys above the new threshold are forgotten, so the memory used stays constant
+independent of the number of distinct keys in the access stream.
+*/
+package mrc
+
+import (
+	"container/heap"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+)
+
+const (
+	modulus = 1 << 24
+
+	// Reuse distances below 'exactBuckets' are counted exactly,
+	// larger ones in buckets growing by 'bucketGrowth'.
+	exactBuckets = 128
+	bucketGrowth = 1.01
+)
+
+type sample struct {
+	key  string
+	hash uint64
+	time int
+}
+
+type Estimator struct {
+	samples   map[string]*sample
+	byHash    sampleHeap
+	tree      fenwick
+	now       int
+	threshold uint64
+	maxKeys   int
+	histogram []float64
+	cold      float64
+	total     float64
+	expected  float64
+	requests  uint64
+	mutex     sync.Mutex
+}
+
+// Option configures optional behaviour of an estimator.
+type Option func(*options)
+
+type options struct {
+	sampleRate float64
+	maxKeys    int
+}
+
+// WithSampleRate sets the initial share of the keys which are sampled.
+// The rate is lowered automatically if the number of tracked keys exceeds
+// its bound. The default rate is 1, all keys are sampled initially.
+func WithSampleRate(rate float64) Option {
+	return func(o *options) {
+		o.sampleRate = rate
+	}
+}
+
+// WithMaxKeys sets the maximum number of tracked keys, bounding the
+// memory used by the estimator. The default is 8192 keys.
+func WithMaxKeys(maxKeys int) Option {
+	return func(o *options) {
+		o.maxKeys = maxKeys
+	}
+}
+
+// New returns the pointer to a new miss ratio curve estimator.
+// The optional 'opts' parameters allow to change the sampling rate
+// and the maximum number of tracked keys.
+func New(opts ...Option) *Estimator {
+	o := options{
+		sampleRate: 1,
+		maxKeys:    8192,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.sampleRate <= 0 || o.sampleRate > 1 {
+		o.sampleRate = 1
+	}
+
+	if o.maxKeys < 1 {
+		o.maxKeys = 1
+	}
+
+	estimator := Estimator{
+		samples:   make(map[string]*sample),
+		tree:      newFenwick(2 * o.maxKeys),
+		threshold: uint64(o.sampleRate * modulus),
+		maxKeys:   o.maxKeys,
+	}
+
+	return &estimator
+}
+
+// Observe records an access to the provided key.
+// The method allows to attach the estimator as observer to a cache.
+func (p *Estimator) Observe(key string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.requests++
+	p.expected += p.rate()
+
+	hash := spatialHash(key)
+
+	if hash >= p.threshold {
+		return
+	}
+
+	if p.now == p.tree.size() {
+		p.compact()
+	}
+
+	p.now++
+	p.total++
+
+	s, ok := p.samples[key]
+	if ok {
+		distance := p.tree.sum(p.now-1) - p.tree.sum(s.time)
+
+		bucket := bucketOf(float64(distance) / p.rate())
+
+		for len(p.histogram) <= bucket {
+			p.histogram = append(p.histogram, 0)
+		}
+
+		p.histogram[bucket]++
+		p.tree.add(s.time, -1)
+	} else {
+		p.cold++
+
+		s = &sample{key: key, hash: hash}
+		p.samples[key] = s
+		heap.Push(&p.byHash, s)
+	}
+
+	s.time = p.now
+	p.tree.add(p.now, 1)
+
+	if len(p.samples) > p.maxKeys {
+		p.lowerThreshold()
+	}
+}
+
+// HitRatio returns the estimated hit ratio of an LRU cache with the provided
+// maximum size, which had served all observed accesses.
+func (p *Estimator) HitRatio(maxSize int) float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.total == 0 || maxSize <= 0 {
+		return 0
+	}
+
+	// The number of sampled accesses differs from the expected one if keys
+	// accessed very often or very rarely are sampled by chance. The difference
+	// is corrected at the smallest reuse distance, like SHARDS-adj does.
+	hits := p.expected - p.total
+
+	size := float64(maxSize)
+
+	for bucket, count := range p.histogram {
+		lower, upper := bucketBounds(bucket)
+
+		if upper <= size {
+			hits += count
+		} else if lower < size {
+			hits += count * (size - lower) / (upper - lower)
+		}
+	}
+
+	ratio := hits / p.expected
+
+	if ratio < 0 {
+		return 0
+	}
+
+	if ratio > 1 {
+		return 1
+	}
+
+	return ratio
+}
+
+// MissRatio returns the estimated miss ratio of an LRU cache with the provided
+// maximum size, which had served all observed accesses.
+func (p *Estimator) MissRatio(maxSize int) float64 {
+	return 1 - p.HitRatio(maxSize)
+}
+
+// Curve returns the estimated hit ratios for the provided cache sizes.
+func (p *Estimator) Curve(sizes ...int) []float64 {
+	curve := make([]float64, len(sizes))
+
+	for i, size := range sizes {
+		curve[i] = p.HitRatio(size)
+	}
+
+	return curve
+}
+
+// Stats returns the number of observed accesses
+// & the current sampling rate.
+func (p *Estimator) Stats() (uint64, float64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.requests, p.rate()
+}
+
+// rate returns the current sampling rate.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Estimator) rate() float64 {
+	return float64(p.threshold) / modulus
+}
+
+// lowerThreshold lowers the threshold to the largest sampled hash, forgets
+// the keys not sampled anymore and rescales the counts to the new rate.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Estimator) lowerThreshold() {
+	oldRate := p.rate()
+
+	p.threshold = p.byHash[0].hash
+
+	for len(p.byHash) > 0 && p.byHash[0].hash >= p.threshold {
+		s := heap.Pop(&p.byHash).(*sample)
+
+		p.tree.add(s.time, -1)
+		delete(p.samples, s.key)
+	}
+
+	scale := p.rate() / oldRate
+
+	for bucket := range p.histogram {
+		p.histogram[bucket] *= scale
+	}
+
+	p.cold *= scale
+	p.total *= scale
+	p.expected *= scale
+}
+
+// compact renumbers the access times of the tracked keys,
+// so that they fit into the Fenwick tree again.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Estimator) compact() {
+	samples := make([]*sample, 0, len(p.samples))
+
+	for _, s := range p.samples {
+		samples = append(samples, s)
+	}
+
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].time < samples[j].time
+	})
+
+	p.tree = newFenwick(p.tree.size())
+
+	for i, s := range samples {
+		s.time = i + 1
+		p.tree.add(s.time, 1)
+	}
+
+	p.now = len(samples)
+}
+
+// bucketOf returns the histogram bucket of the provided reuse distance.
+func bucketOf(distance float64) int {
+	if distance < exactBuckets {
+		return int(distance)
+	}
+
+	return exactBuckets + int(math.Log(distance/exactBuckets)/math.Log(bucketGrowth))
+}
+
+// bucketBounds returns the range of reuse distances counted by the provided bucket.
+func bucketBounds(bucket int) (float64, float64) {
+	if bucket < exactBuckets {
+		return float64(bucket), float64(bucket + 1)
+	}
+
+	lower := exactBuckets * math.Pow(bucketGrowth, float64(bucket-exactBuckets))
+
+	return lower, lower * bucketGrowth
+}
+
+// spatialHash maps the key uniformly to the range [0, modulus).
+// The FNV hash is finalized like in MurmurHash3, as its low bits
+// hardly differ for short keys.
+func spatialHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+
+	hash := h.Sum64()
+	hash ^= hash >> 33
+	hash *= 0xff51afd7ed558ccd
+	hash ^= hash >> 33
+	hash *= 0xc4ceb9fe1a85ec53
+	hash ^= hash >> 33
+
+	return hash % modulus
+}
+
+// sampleHeap orders the tracked keys by their hash, the largest first.
+type sampleHeap []*sample
+
+func (h sampleHeap) Len() int           { return len(h) }
+func (h sampleHeap) Less(i, j int) bool { return h[i].hash > h[j].hash }
+func (h sampleHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *sampleHeap) Push(x any) {
+	*h = append(*h, x.(*sample))
+}
+
+func (h *sampleHeap) Pop() any {
+	old := *h
+	n := len(old)
+	s := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+
+	return s
+}
+
+// fenwick is a binary indexed tree counting the keys by their last access time.
+type fenwick []int
+
+func newFenwick(size int) fenwick {
+	return make(fenwick, size+1)
+}
+
+func (f fenwick) size() int {
+	return len(f) - 1
+}
+
+// add adds the provided delta at the provided position, starting at 1.
+func (f fenwick) add(position int, delta int) {
+	for ; position < len(f); position += position & -position {
+		f[position] += delta
+	}
+}
+
+// sum returns the sum of the positions up to & including the provided one.
+func (f fenwick) sum(position int) int {
+	total := 0
+
+	for ; position > 0; position -= position & -position {
+		total += f[position]
+	}
+
+	return total
+}
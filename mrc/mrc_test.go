@@ -0,0 +1,71 @@
+package mrc_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"github.com/piccobit/generics/lrucache"
+	"github.com/piccobit/generics/mrc"
+)
+
+func ExampleEstimator_HitRatio() {
+	var err error
+
+	estimator := mrc.New()
+
+	myIntCache := lrucache.New[int](10)
+	myIntCache.AddObserver(estimator)
+
+	// Loop 10 times over 100 keys.
+	for n := 0; n < 10; n++ {
+		for i := 0; i < 100; i++ {
+			id := strconv.Itoa(i)
+
+			if _, ok := myIntCache.Get(id); !ok {
+				err = myIntCache.AddByID(id, i)
+				if err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+				}
+			}
+		}
+	}
+
+	for _, maxSize := range []int{10, 99, 100, 1000} {
+		fmt.Printf("%d: %.2f\n", maxSize, estimator.HitRatio(maxSize))
+	}
+	// Output:
+	// 10: 0.00
+	// 99: 0.00
+	// 100: 0.90
+	// 1000: 0.90
+}
+
+func ExampleWithMaxKeys() {
+	exact := mrc.New(mrc.WithMaxKeys(100000))
+	sampled := mrc.New(mrc.WithMaxKeys(1000))
+
+	zipf := rand.NewZipf(rand.New(rand.NewSource(42)), 1.1, 1, 99999)
+
+	for i := 0; i < 500000; i++ {
+		key := strconv.FormatUint(zipf.Uint64(), 10)
+
+		exact.Observe(key)
+		sampled.Observe(key)
+	}
+
+	_, rate := sampled.Stats()
+	fmt.Printf("Sampled: %v\n", rate < 0.1)
+
+	for _, maxSize := range []int{1000, 10000, 50000} {
+		deviation := math.Abs(exact.HitRatio(maxSize) - sampled.HitRatio(maxSize))
+		fmt.Printf("%d: %v\n", maxSize, deviation < 0.05)
+	}
+	// Output:
+	// Sampled: true
+	// 1000: true
+	// 10000: true
+	// 50000: true
+}
@@ -0,0 +1,110 @@
+package peercache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// BasePath is the path below which a node answers the requests of other peers.
+// A request for the key 'k' of the group 'g' is sent as 'GET /_peercache/g/k',
+// both parts path escaped, and answered by the encoded value.
+const BasePath = "/_peercache/"
+
+// HTTPTransport sends the requests to other peers using HTTP.
+// The peers are named by their base URL, e.g. 'http://10.0.0.1:8080'.
+type HTTPTransport struct {
+	client *http.Client
+}
+
+// NewHTTPTransport returns the pointer to a new HTTP transport.
+// If the provided client is nil, the default client is used.
+func NewHTTPTransport(client *http.Client) *HTTPTransport {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	transport := HTTPTransport{client: client}
+
+	return &transport
+}
+
+// Fetch sends the provided request to the peer with the provided base URL.
+func (p *HTTPTransport) Fetch(ctx context.Context, peer string, request Request) (Response, error) {
+	target := strings.TrimSuffix(peer, "/") + BasePath + url.PathEscape(request.Group) + "/" + url.PathEscape(request.Key)
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return Response{}, err
+	}
+
+	httpResponse, err := p.client.Do(httpRequest)
+	if err != nil {
+		return Response{}, err
+	}
+
+	defer httpResponse.Body.Close()
+
+	body, err := io.ReadAll(httpResponse.Body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if httpResponse.StatusCode != http.StatusOK {
+		return Response{}, &PeerError{Peer: peer, Message: strings.TrimSpace(string(body))}
+	}
+
+	return Response{Value: body}, nil
+}
+
+// ServeHTTP answers the requests of other peers sent by an HTTP transport,
+// so the node can be registered at an HTTP server using 'BasePath'.
+func (p *Node) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.EscapedPath()
+
+	if r.Method != http.MethodGet || !strings.HasPrefix(path, BasePath) {
+		http.NotFound(w, r)
+
+		return
+	}
+
+	parts := strings.SplitN(path[len(BasePath):], "/", 2)
+	if len(parts) != 2 {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+
+		return
+	}
+
+	group, err := url.PathUnescape(parts[0])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	key, err := url.PathUnescape(parts[1])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	response, err := p.Serve(r.Context(), Request{Group: group, Key: key})
+	if err != nil {
+		var unknownGroupError *UnknownGroupError
+
+		if errors.As(err, &unknownGroupError) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		} else {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(response.Value)
+}
@@ -0,0 +1,422 @@
+/*
+Package peercache shares the caches of several replicas of a service, so that a key is only
+loaded by one of them. A consistent hash ring selects the peer owning a key. The owner loads
+missing values & keeps them in its LRU cache, the other peers fetch the values from the owner
+using a transport. Values fetched repeatedly are kept in a small local LRU cache, the hot
+mirror, so popular keys don't cause a request to the owner every time.
+
+As the mirrored values are never invalidated, the values of a key must not change.
+
+A node represents the local peer, the groups registered at a node are separate caches with
+their own loader. Nodes talk to each other using HTTP or, for tests, the in-memory transport.
+*/
+package peercache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/piccobit/generics/codec"
+	"github.com/piccobit/generics/lrucache"
+)
+
+// DefaultHotThreshold is the default number of fetches from the owner
+// after which a key is mirrored.
+const DefaultHotThreshold = 2
+
+// Loader loads the value with the provided key on the owner.
+type Loader[T any] func(ctx context.Context, key string) (T, error)
+
+// Stats holds the statistics of a group.
+type Stats struct {
+	// Gets counts the calls of 'Get'.
+	Gets uint
+	// LocalHits & HotHits count the values found in the LRU cache
+	// and in the hot mirror.
+	LocalHits uint
+	HotHits   uint
+	// PeerFetches & PeerErrors count the requests to the owners.
+	PeerFetches uint
+	PeerErrors  uint
+	// Loads & LoadErrors count the calls of the loader.
+	Loads      uint
+	LoadErrors uint
+	// Deduplicated counts the calls which waited for a running
+	// fetch or load of the same key.
+	Deduplicated uint
+	// ServerRequests counts the requests of other peers.
+	ServerRequests uint
+}
+
+// Option configures optional behaviour of a node or a group.
+type Option func(*options)
+
+type options struct {
+	replicas     int
+	hotSize      int
+	hotThreshold uint
+}
+
+// WithReplicas sets the number of points of every peer on the ring of a node.
+// The default is 'DefaultReplicas'.
+func WithReplicas(replicas int) Option {
+	return func(o *options) {
+		o.replicas = replicas
+	}
+}
+
+// WithHotSize sets the maximum size of the hot mirror of a group.
+// The default is an eighth of the size of the group, 0 disables the mirror.
+func WithHotSize(hotSize int) Option {
+	return func(o *options) {
+		o.hotSize = hotSize
+	}
+}
+
+// WithHotThreshold sets the number of fetches from the owner after which
+// a key is mirrored by a group. The default is 'DefaultHotThreshold'.
+func WithHotThreshold(threshold uint) Option {
+	return func(o *options) {
+		o.hotThreshold = threshold
+	}
+}
+
+type server interface {
+	serve(ctx context.Context, key string) ([]byte, error)
+}
+
+type Node struct {
+	self      string
+	ring      *Ring
+	transport Transport
+	groups    map[string]server
+	mutex     sync.RWMutex
+}
+
+// NewNode returns the pointer to a new node for the local peer with
+// the provided name, using the provided transport to reach the other peers.
+// The optional 'opts' parameters allow to change the number of replicas
+// on the ring.
+func NewNode(self string, transport Transport, opts ...Option) *Node {
+	o := options{replicas: DefaultReplicas}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	node := Node{
+		self:      self,
+		ring:      NewRing(o.replicas),
+		transport: transport,
+		groups:    make(map[string]server),
+	}
+
+	return &node
+}
+
+// Self returns the name of the local peer.
+func (p *Node) Self() string {
+	return p.self
+}
+
+// SetPeers replaces the peers sharing the keys, including the local one.
+// As long as no peers are set, all keys are owned by the local peer.
+func (p *Node) SetPeers(peers ...string) {
+	p.ring.Set(peers...)
+}
+
+// Owner returns the peer owning the provided key.
+func (p *Node) Owner(key string) string {
+	if peer, ok := p.ring.Get(key); ok {
+		return peer
+	}
+
+	return p.self
+}
+
+// Serve answers the request of another peer. The value is loaded by the
+// local peer, the request is never forwarded to a further peer.
+func (p *Node) Serve(ctx context.Context, request Request) (Response, error) {
+	p.mutex.RLock()
+	group, ok := p.groups[request.Group]
+	p.mutex.RUnlock()
+
+	if !ok {
+		return Response{}, &UnknownGroupError{Group: request.Group}
+	}
+
+	value, err := group.serve(ctx, request.Key)
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{Value: value}, nil
+}
+
+type call[T any] struct {
+	done  chan struct{}
+	value T
+	err   error
+}
+
+type Group[T any] struct {
+	name         string
+	node         *Node
+	loader       Loader[T]
+	codec        codec.Codec[T]
+	main         *lrucache.LRUCache[T]
+	hot          *lrucache.LRUCache[T]
+	hotThreshold uint
+	counts       map[string]uint
+	maxCounts    int
+	fetches      map[string]*call[T]
+	loads        map[string]*call[T]
+	stats        Stats
+	mutex        sync.Mutex
+}
+
+// NewGroup returns the pointer to a new group registered at the provided node
+// by the provided name. A group registered before by the same name is replaced.
+// The 'maxSize' parameter allows to specify the maximum size of the LRU cache
+// holding the values owned by the local peer, it must be at least 1.
+// The 'valueCodec' parameter defines how values are sent to other peers, if it is
+// nil the values are sent as JSON.
+// The optional 'opts' parameters allow to configure the hot mirror.
+func NewGroup[T any](node *Node, name string, maxSize int, loader Loader[T], valueCodec codec.Codec[T], opts ...Option) *Group[T] {
+	o := options{
+		hotSize:      maxSize / 8,
+		hotThreshold: DefaultHotThreshold,
+	}
+
+	if o.hotSize < 1 {
+		o.hotSize = 1
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if valueCodec == nil {
+		valueCodec = codec.JSONCodec[T]{}
+	}
+
+	group := Group[T]{
+		name:         name,
+		node:         node,
+		loader:       loader,
+		codec:        valueCodec,
+		main:         lrucache.New[T](maxSize),
+		hotThreshold: o.hotThreshold,
+		counts:       make(map[string]uint),
+		maxCounts:    8 * o.hotSize,
+		fetches:      make(map[string]*call[T]),
+		loads:        make(map[string]*call[T]),
+	}
+
+	if o.hotSize > 0 {
+		group.hot = lrucache.New[T](o.hotSize)
+	}
+
+	node.mutex.Lock()
+	node.groups[name] = &group
+	node.mutex.Unlock()
+
+	return &group
+}
+
+// Name returns the name of the group.
+func (p *Group[T]) Name() string {
+	return p.name
+}
+
+// Get returns the value with the provided key. The value is taken from the
+// local caches, fetched from the owner or, if the local peer owns the key
+// or the owner failed, loaded. Concurrent calls for the same key share
+// a single fetch or load.
+func (p *Group[T]) Get(ctx context.Context, key string) (T, error) {
+	p.mutex.Lock()
+
+	p.stats.Gets++
+
+	if value, ok := p.main.Get(key); ok {
+		p.stats.LocalHits++
+		p.mutex.Unlock()
+
+		return value, nil
+	}
+
+	if p.hot != nil {
+		if value, ok := p.hot.Get(key); ok {
+			p.stats.HotHits++
+			p.mutex.Unlock()
+
+			return value, nil
+		}
+	}
+
+	if c, ok := p.fetches[key]; ok {
+		p.stats.Deduplicated++
+		p.mutex.Unlock()
+
+		return wait(ctx, c)
+	}
+
+	c := &call[T]{done: make(chan struct{})}
+	p.fetches[key] = c
+
+	p.mutex.Unlock()
+
+	c.value, c.err = p.fetch(ctx, key)
+
+	p.mutex.Lock()
+	delete(p.fetches, key)
+	p.mutex.Unlock()
+
+	close(c.done)
+
+	return c.value, c.err
+}
+
+// Stats returns the statistics of the group.
+func (p *Group[T]) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.stats
+}
+
+// fetch requests the value from the owner of the key,
+// falling back to the loader.
+func (p *Group[T]) fetch(ctx context.Context, key string) (T, error) {
+	owner := p.node.Owner(key)
+
+	if owner != p.node.self {
+		response, err := p.node.transport.Fetch(ctx, owner, Request{Group: p.name, Key: key})
+
+		var value T
+
+		if err == nil {
+			value, err = p.codec.Decode(response.Value)
+		}
+
+		p.mutex.Lock()
+
+		if err == nil {
+			p.stats.PeerFetches++
+			p.mirror(key, value)
+		} else {
+			p.stats.PeerErrors++
+		}
+
+		p.mutex.Unlock()
+
+		if err == nil {
+			return value, nil
+		}
+
+		if ctx.Err() != nil {
+			return value, ctx.Err()
+		}
+	}
+
+	return p.load(ctx, key)
+}
+
+// serve answers the request of another peer.
+func (p *Group[T]) serve(ctx context.Context, key string) ([]byte, error) {
+	p.mutex.Lock()
+	p.stats.ServerRequests++
+	p.mutex.Unlock()
+
+	value, err := p.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.codec.Encode(value)
+}
+
+// load returns the value from the LRU cache or loads it,
+// concurrent calls for the same key share a single load.
+func (p *Group[T]) load(ctx context.Context, key string) (T, error) {
+	p.mutex.Lock()
+
+	if value, ok := p.main.Get(key); ok {
+		p.mutex.Unlock()
+
+		return value, nil
+	}
+
+	if c, ok := p.loads[key]; ok {
+		p.stats.Deduplicated++
+		p.mutex.Unlock()
+
+		return wait(ctx, c)
+	}
+
+	c := &call[T]{done: make(chan struct{})}
+	p.loads[key] = c
+	p.stats.Loads++
+
+	p.mutex.Unlock()
+
+	c.value, c.err = p.loader(ctx, key)
+
+	p.mutex.Lock()
+
+	delete(p.loads, key)
+
+	if c.err != nil {
+		p.stats.LoadErrors++
+	} else {
+		_ = p.main.AddByID(key, c.value)
+	}
+
+	p.mutex.Unlock()
+
+	close(c.done)
+
+	return c.value, c.err
+}
+
+// mirror counts the fetch of the provided key and adds the value to the hot
+// mirror once the threshold is reached. If too many keys are counted, all
+// counts are halved, so keys which were popular long ago are forgotten.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Group[T]) mirror(key string, value T) {
+	if p.hot == nil {
+		return
+	}
+
+	p.counts[key]++
+
+	if p.counts[key] >= p.hotThreshold {
+		delete(p.counts, key)
+		_ = p.hot.AddByID(key, value)
+
+		return
+	}
+
+	if len(p.counts) > p.maxCounts {
+		for id, count := range p.counts {
+			if count/2 == 0 {
+				delete(p.counts, id)
+			} else {
+				p.counts[id] = count / 2
+			}
+		}
+	}
+}
+
+// wait waits for the provided call to finish.
+func wait[T any](ctx context.Context, c *call[T]) (T, error) {
+	select {
+	case <-c.done:
+		return c.value, c.err
+	case <-ctx.Done():
+		var dummy T
+
+		return dummy, ctx.Err()
+	}
+}
@@ -0,0 +1,177 @@
+package peercache_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/piccobit/generics/peercache"
+)
+
+func ExampleRing_Get() {
+	ring := peercache.NewRing(0)
+	ring.Set("alpha", "beta", "gamma")
+
+	owner, _ := ring.Get("Ferrari")
+	fmt.Printf("Points: %d\n", ring.Len())
+	fmt.Printf("Owner: %s\n", owner)
+
+	// Removing a peer only moves the keys of that peer.
+	ring.Set("alpha", "beta", "gamma", "delta")
+
+	newOwner, _ := ring.Get("Ferrari")
+	fmt.Printf("Moved: %t\n", newOwner != owner && newOwner != "delta")
+	// Output:
+	// Points: 150
+	// Owner: beta
+	// Moved: false
+}
+
+func ExampleGroup_Get() {
+	var loads int32
+
+	loader := func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+
+		return strings.ToUpper(key), nil
+	}
+
+	transport := peercache.NewMemoryTransport()
+	peers := []string{"alpha", "beta", "gamma"}
+	nodes := make([]*peercache.Node, 0, len(peers))
+	groups := make([]*peercache.Group[string], 0, len(peers))
+
+	for _, peer := range peers {
+		node := peercache.NewNode(peer, transport)
+		node.SetPeers(peers...)
+		transport.Register(node)
+
+		nodes = append(nodes, node)
+		groups = append(groups, peercache.NewGroup[string](node, "names", 100, loader, nil))
+	}
+
+	owner := nodes[0].Owner("ferrari")
+	fmt.Printf("Owner: %s\n", owner)
+
+	for _, group := range groups {
+		value, err := group.Get(context.Background(), "ferrari")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Get: %s\n", value)
+	}
+
+	fmt.Printf("Loads: %d\n", atomic.LoadInt32(&loads))
+	fmt.Printf("Requests to the owner: %d\n", transport.Calls(owner))
+	// Output:
+	// Owner: gamma
+	// Get: FERRARI
+	// Get: FERRARI
+	// Get: FERRARI
+	// Loads: 1
+	// Requests to the owner: 2
+}
+
+func ExampleWithHotThreshold() {
+	loader := func(ctx context.Context, key string) (string, error) {
+		return strings.ToUpper(key), nil
+	}
+
+	transport := peercache.NewMemoryTransport()
+
+	owner := peercache.NewNode("beta", transport)
+	owner.SetPeers("alpha", "beta")
+	transport.Register(owner)
+	peercache.NewGroup[string](owner, "names", 100, loader, nil)
+
+	node := peercache.NewNode("alpha", transport)
+	node.SetPeers("alpha", "beta")
+	transport.Register(node)
+	group := peercache.NewGroup[string](node, "names", 100, loader, nil, peercache.WithHotThreshold(2))
+
+	for i := 0; i < 5; i++ {
+		_, _ = group.Get(context.Background(), "ferrari")
+	}
+
+	stats := group.Stats()
+	fmt.Printf("Peer fetches: %d, Hot hits: %d\n", stats.PeerFetches, stats.HotHits)
+
+	// If the owner fails, the value is loaded locally.
+	transport.Unregister("beta")
+
+	value, err := group.Get(context.Background(), "beetle")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	stats = group.Stats()
+	fmt.Printf("Get: %s, Peer errors: %d, Loads: %d\n", value, stats.PeerErrors, stats.Loads)
+	// Output:
+	// Peer fetches: 2, Hot hits: 3
+	// Get: BEETLE, Peer errors: 1, Loads: 1
+}
+
+func ExampleHTTPTransport() {
+	var loads int32
+
+	loader := func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+
+		return strings.ToUpper(key), nil
+	}
+
+	transport := peercache.NewHTTPTransport(nil)
+	nodes := make([]*peercache.Node, 2)
+	servers := make([]*httptest.Server, 2)
+	peers := make([]string, 2)
+
+	// The names of the peers are the URLs of the servers,
+	// so the nodes are created after the servers.
+	for i := range nodes {
+		i := i
+
+		servers[i] = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			nodes[i].ServeHTTP(w, r)
+		}))
+		peers[i] = servers[i].URL
+
+		defer servers[i].Close()
+	}
+
+	for i := range nodes {
+		nodes[i] = peercache.NewNode(peers[i], transport)
+		nodes[i].SetPeers(peers...)
+	}
+
+	groups := make([]*peercache.Group[string], 2)
+
+	for i := range nodes {
+		groups[i] = peercache.NewGroup[string](nodes[i], "names", 100, loader, nil)
+	}
+
+	for _, key := range []string{"ferrari", "beetle", "trabant / 601"} {
+		for _, group := range groups {
+			value, err := group.Get(context.Background(), key)
+			if err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+			}
+
+			fmt.Printf("Get: %s\n", value)
+		}
+	}
+
+	fmt.Printf("Loads: %d\n", atomic.LoadInt32(&loads))
+	// Output:
+	// Get: FERRARI
+	// Get: FERRARI
+	// Get: BEETLE
+	// Get: BEETLE
+	// Get: TRABANT / 601
+	// Get: TRABANT / 601
+	// Loads: 3
+}
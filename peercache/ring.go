@@ -0,0 +1,94 @@
+package peercache
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// DefaultReplicas is the default number of points of every peer on the ring.
+const DefaultReplicas = 50
+
+// Ring is a consistent hash ring mapping keys to peers. Every peer is placed
+// on the ring several times, so the keys are spread evenly and adding or
+// removing a peer only moves the keys of that peer.
+type Ring struct {
+	replicas int
+	points   []uint32
+	peers    map[uint32]string
+	mutex    sync.RWMutex
+}
+
+// NewRing returns the pointer to a new, empty ring.
+// The 'replicas' parameter allows to specify the number of points
+// of every peer on the ring, if it is 0 'DefaultReplicas' is used.
+func NewRing(replicas int) *Ring {
+	if replicas <= 0 {
+		replicas = DefaultReplicas
+	}
+
+	ring := Ring{
+		replicas: replicas,
+		peers:    make(map[uint32]string),
+	}
+
+	return &ring
+}
+
+// Set replaces the peers of the ring with the provided ones.
+func (p *Ring) Set(peers ...string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.points = make([]uint32, 0, len(peers)*p.replicas)
+	p.peers = make(map[uint32]string, len(peers)*p.replicas)
+
+	for _, peer := range peers {
+		for i := 0; i < p.replicas; i++ {
+			point := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + peer))
+
+			if _, ok := p.peers[point]; ok {
+				continue
+			}
+
+			p.points = append(p.points, point)
+			p.peers[point] = peer
+		}
+	}
+
+	sort.Slice(p.points, func(i, j int) bool {
+		return p.points[i] < p.points[j]
+	})
+}
+
+// Get returns the peer owning the provided key.
+// If the ring is empty 'false' is returned.
+func (p *Ring) Get(key string) (string, bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.points) == 0 {
+		return "", false
+	}
+
+	hash := crc32.ChecksumIEEE([]byte(key))
+
+	i := sort.Search(len(p.points), func(i int) bool {
+		return p.points[i] >= hash
+	})
+
+	if i == len(p.points) {
+		i = 0
+	}
+
+	return p.peers[p.points[i]], true
+}
+
+// Len returns the number of points on the ring.
+func (p *Ring) Len() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.points)
+}
@@ -0,0 +1,123 @@
+package peercache
+
+import (
+	"context"
+	"sync"
+)
+
+// Request asks the owner of a key for its value.
+type Request struct {
+	Group string
+	Key   string
+}
+
+// Response carries the encoded value of a key.
+type Response struct {
+	Value []byte
+}
+
+// Transport sends requests to other peers. Every call is a single
+// request answered by a single response, so besides the provided
+// transports a gRPC service with a unary method can be used as well.
+type Transport interface {
+	Fetch(ctx context.Context, peer string, request Request) (Response, error)
+}
+
+type UnknownPeerError struct {
+	Peer string
+}
+
+type UnknownGroupError struct {
+	Group string
+}
+
+// PeerError is returned if the owner failed to provide a value.
+type PeerError struct {
+	Peer    string
+	Message string
+}
+
+func (e *UnknownPeerError) Error() string {
+	return "Unknown peer error: " + e.Peer
+}
+
+func (e *UnknownGroupError) Error() string {
+	return "Unknown group error: " + e.Group
+}
+
+func (e *PeerError) Error() string {
+	return "Peer error: " + e.Peer + ": " + e.Message
+}
+
+// MemoryTransport connects nodes running in the same process,
+// e.g. to test a set of peers without a network.
+type MemoryTransport struct {
+	nodes map[string]*Node
+	calls map[string]uint
+	mutex sync.RWMutex
+}
+
+// NewMemoryTransport returns the pointer to a new in-memory transport.
+func NewMemoryTransport() *MemoryTransport {
+	transport := MemoryTransport{
+		nodes: make(map[string]*Node),
+		calls: make(map[string]uint),
+	}
+
+	return &transport
+}
+
+// Register makes the provided node reachable by its name.
+func (p *MemoryTransport) Register(node *Node) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.nodes[node.Self()] = node
+}
+
+// Unregister makes the node with the provided name unreachable,
+// e.g. to simulate a failed peer.
+func (p *MemoryTransport) Unregister(peer string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.nodes, peer)
+}
+
+// Fetch sends the provided request to the node with the provided name.
+func (p *MemoryTransport) Fetch(ctx context.Context, peer string, request Request) (Response, error) {
+	p.mutex.Lock()
+
+	node, ok := p.nodes[peer]
+	if ok {
+		p.calls[peer]++
+	}
+
+	p.mutex.Unlock()
+
+	if !ok {
+		return Response{}, &UnknownPeerError{Peer: peer}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return Response{}, err
+	}
+
+	response, err := node.Serve(ctx, request)
+	if err != nil {
+		return Response{}, &PeerError{Peer: peer, Message: err.Error()}
+	}
+
+	// The value is copied like it would be by a network.
+	response.Value = append([]byte(nil), response.Value...)
+
+	return response, nil
+}
+
+// Calls returns the number of requests sent to the node with the provided name.
+func (p *MemoryTransport) Calls(peer string) uint {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.calls[peer]
+}
@@ -0,0 +1,151 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/piccobit/generics/event"
+)
+
+// FromChan returns the pointer to a new unbounded queue which is fed with
+// the values received from the provided channel. The values are pushed
+// until the channel is closed or the context is cancelled.
+func FromChan[T any](ctx context.Context, in <-chan T) *Queue[T] {
+	queue := New[T](0)
+
+	go queue.pump(ctx, in)
+
+	return queue
+}
+
+// Chan returns a channel which delivers the elements of the queue in
+// FIFO order. The elements are handed off one at a time: the next element
+// is removed from the queue as soon as it is available and kept by the
+// channel until it is received, so while the receiver is busy 'Length()'
+// doesn't include this element and the queue keeps buffering the others.
+// The channel is closed as soon as the context is cancelled; an element
+// waiting for its receiver at that point is put back to the front of the queue.
+func (p *Queue[T]) Chan(ctx context.Context) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			changed := p.wait()
+
+			value, err := p.Pop()
+			if err != nil {
+				select {
+				case <-changed:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				p.requeue(value)
+
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Sink returns a channel whose received values are pushed on the queue.
+// If the queue is limited in its size and rejects values because of an
+// overflow, the sink stops receiving until space becomes available, so
+// senders are blocked instead of losing values. This only applies to the
+// 'RejectAll' & 'AcceptPartial' overflow policies: with 'DropOldest' or
+// 'DropNewest' the pushes succeed and values are dropped according to the
+// policy without blocking the senders. The sink stops when the
+// returned channel is closed or the context is cancelled; a value waiting
+// for space at that point is discarded. Senders must not send on the
+// channel after the context has been cancelled.
+func (p *Queue[T]) Sink(ctx context.Context) chan<- T {
+	in := make(chan T)
+
+	go p.pump(ctx, in)
+
+	return in
+}
+
+// pump pushes the values received from the provided channel on the queue
+// until the channel is closed or the context is cancelled.
+func (p *Queue[T]) pump(ctx context.Context, in <-chan T) {
+	for {
+		select {
+		case value, ok := <-in:
+			if !ok {
+				return
+			}
+
+			if !p.pushWait(ctx, value) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pushWait pushes the provided value on the queue and waits for free space
+// as long as the queue rejects it because of an overflow. It returns 'false'
+// if the context was cancelled before the value could be pushed.
+func (p *Queue[T]) pushWait(ctx context.Context, value T) bool {
+	var overflowError *OverflowError
+
+	for {
+		changed := p.wait()
+
+		err := p.Push(value)
+		if !errors.As(err, &overflowError) {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// requeue puts the provided value back to the front of the queue,
+// regardless of the maximum size of the queue.
+func (p *Queue[T]) requeue(value T) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.content = append([]T{value}, p.content...)
+	p.notify()
+	p.publish(event.Pushed, value)
+}
+
+// wait returns a channel which is closed on the next modification
+// of the queue.
+func (p *Queue[T]) wait() <-chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.changed == nil {
+		p.changed = make(chan struct{})
+	}
+
+	return p.changed
+}
+
+// notify wakes up all goroutines waiting for a modification of the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Queue[T]) notify() {
+	if p.changed != nil {
+		close(p.changed)
+		p.changed = nil
+	}
+}
@@ -0,0 +1,25 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/piccobit/generics/event"
+)
+
+// Subscribe reports the pushes, pops and drops of the queue made after the
+// subscription, until the context is cancelled. See 'event.Broker.Subscribe'
+// for the delivery of the events and the options.
+func (p *Queue[T]) Subscribe(ctx context.Context, opts ...event.Option) <-chan event.Event[T] {
+	return p.events.Subscribe(ctx, opts...)
+}
+
+// publish reports the provided values to the subscribers.
+func (p *Queue[T]) publish(kind event.Kind, values ...T) {
+	if !p.events.Active() {
+		return
+	}
+
+	for _, value := range values {
+		p.events.Publish(event.Event[T]{Kind: kind, Value: value})
+	}
+}
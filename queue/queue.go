@@ -8,16 +8,123 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/piccobit/generics/event"
 )
 
 type Queue[T any] struct {
 	content []T
 	maxSize int
+	policy  OverflowPolicy
+	stats   OverflowStats
+	changed chan struct{}
+	events  event.Broker[T]
 	mutex   sync.RWMutex
 }
 
+// OverflowPolicy defines how a size limited queue handles
+// pushes which would exceed its maximum size.
+type OverflowPolicy int
+
+const (
+	// RejectAll rejects the whole push with an 'Overflow error'
+	// if not all elements fit into the queue.
+	RejectAll OverflowPolicy = iota
+	// AcceptPartial accepts as many elements as fit into the queue
+	// and returns an 'Overflow error' for the remaining ones.
+	AcceptPartial
+	// DropOldest accepts all elements and drops the oldest ones
+	// of the queue to make place for them.
+	DropOldest
+	// DropNewest accepts as many elements as fit into the queue
+	// and silently drops the remaining ones.
+	DropNewest
+)
+
+// OverflowStats holds the number of elements which were not stored
+// or were removed because of an overflow, separated by policy.
+type OverflowStats struct {
+	Rejected      uint
+	Truncated     uint
+	DroppedOldest uint
+	DroppedNewest uint
+}
+
+// apply applies the overflow policy to 'n' elements pushed on a queue which
+// holds 'length' elements and is limited to 'maxSize' elements. It returns the
+// number of the oldest elements to drop, the range of the pushed elements to
+// store and the overflow error, and counts the elements not stored or dropped
+// in the provided statistics.
+func (policy OverflowPolicy) apply(maxSize int, length int, n int, stats *OverflowStats) (int, int, int, error) {
+	if maxSize <= 0 {
+		return 0, 0, n, nil
+	}
+
+	free := maxSize - length
+	if free < 0 {
+		free = 0
+	}
+
+	switch policy {
+	case AcceptPartial, DropNewest:
+		if n <= free {
+			return 0, 0, n, nil
+		}
+
+		if policy == DropNewest {
+			stats.DroppedNewest += uint(n - free)
+
+			return 0, 0, free, nil
+		}
+
+		stats.Truncated += uint(n - free)
+
+		return 0, 0, free, &OverflowError{}
+	case DropOldest:
+		first := 0
+
+		if n > maxSize {
+			first = n - maxSize
+			stats.DroppedOldest += uint(first)
+		}
+
+		drop := length + n - first - maxSize
+		if drop < 0 {
+			drop = 0
+		}
+
+		stats.DroppedOldest += uint(drop)
+
+		return drop, first, n, nil
+	default:
+		if free == 0 || n > free {
+			stats.Rejected += uint(n)
+
+			return 0, 0, 0, &OverflowError{}
+		}
+
+		return 0, 0, n, nil
+	}
+}
+
+// Option configures optional behaviour of a queue.
+type Option func(*options)
+
+type options struct {
+	policy OverflowPolicy
+}
+
+// WithOverflowPolicy sets the policy used if a push would
+// overflow the queue. The default policy is 'RejectAll'.
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
 type UnderflowError struct{}
 type OverflowError struct{}
+type NegativeCountError struct{}
 
 func (e *UnderflowError) Error() string {
 	return "Underflow error"
@@ -27,12 +134,27 @@ func (e *OverflowError) Error() string {
 	return "Overflow error"
 }
 
+func (e *NegativeCountError) Error() string {
+	return "Negative count error"
+}
+
 // New returns the pointer to a new queue.
 // The 'maxSize' parameter allows to specify a
 // maximum size for the queue. Setting this to 0
 // allows the queue to grow infinitely.
-func New[T any](maxSize int) *Queue[T] {
-	queue := Queue[T]{maxSize: maxSize}
+// The optional 'opts' parameters allow to change
+// the behaviour of the queue, e.g. its overflow policy.
+func New[T any](maxSize int, opts ...Option) *Queue[T] {
+	o := options{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	queue := Queue[T]{
+		maxSize: maxSize,
+		policy:  o.policy,
+	}
 
 	return &queue
 }
@@ -40,21 +162,42 @@ func New[T any](maxSize int) *Queue[T] {
 // Push pushes the given arguments on the provided queue.
 // An overflow error is returned in case the queue is
 // limited in its size and the push would overflow the queue.
+// How the arguments are handled in case of an overflow
+// depends on the overflow policy of the queue.
 func (p *Queue[T]) Push(args ...T) error {
+	_, err := p.Offer(args...)
+
+	return err
+}
+
+// Offer pushes the given arguments on the provided queue and returns
+// the number of arguments which have been stored in the queue.
+// The overflow policy of the queue defines if an overflow rejects all
+// arguments, stores as many arguments as fit or drops elements to make
+// place for them. An overflow error is only returned by the 'RejectAll'
+// and 'AcceptPartial' policies.
+func (p *Queue[T]) Offer(args ...T) (int, error) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
-	if p.maxSize > 0 && len(p.content) >= p.maxSize {
-		return &OverflowError{}
-	}
+	drop, first, last, err := p.policy.apply(p.maxSize, len(p.content), len(args), &p.stats)
+
+	p.dropN(drop, event.Dropped)
 
-	if p.maxSize > 0 && (len(p.content)+len(args)) > p.maxSize {
-		return &OverflowError{}
+	if last > first {
+		p.append(args[first:last]...)
 	}
 
-	p.content = append(p.content, args...)
+	return last - first, err
+}
 
-	return nil
+// OverflowStats returns the number of elements which were rejected
+// or dropped because of an overflow of the queue.
+func (p *Queue[T]) OverflowStats() OverflowStats {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.stats
 }
 
 // String implements the Stringer interface to provide a
@@ -95,6 +238,8 @@ func (p *Queue[T]) Pop() (T, error) {
 	value := p.content[0]
 
 	p.content = p.content[1:]
+	p.notify()
+	p.publish(event.Popped, value)
 
 	return value, nil
 }
@@ -110,7 +255,11 @@ func (p *Queue[T]) Drop() error {
 		return &UnderflowError{}
 	}
 
+	value := p.content[0]
+
 	p.content = p.content[1:]
+	p.notify()
+	p.publish(event.Dropped, value)
 
 	return nil
 }
@@ -144,3 +293,172 @@ func (p *Queue[T]) Peek() (T, error) {
 func (p *Queue[T]) GetQueue() []T {
 	return p.content
 }
+
+// PopN pops the first 'n' elements of the queue and returns them to the caller
+// in the order in which they were pushed.
+// The operation is atomic: if the queue holds fewer than 'n' elements
+// an 'Underflow error' is returned and the queue is left untouched.
+// A negative 'n' returns a 'Negative count error'.
+func (p *Queue[T]) PopN(n int) ([]T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n < 0 {
+		return nil, &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return nil, &UnderflowError{}
+	}
+
+	return p.popN(n), nil
+}
+
+// PopUpToN pops at most 'n' elements of the queue and returns them to the
+// caller together with the number of elements taken. Contrary to PopN
+// no error is returned if the queue holds fewer than 'n' elements.
+func (p *Queue[T]) PopUpToN(n int) ([]T, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	values := p.popN(p.available(n))
+
+	return values, len(values)
+}
+
+// PopAll pops all elements of the queue and returns them to the caller
+// in the order in which they were pushed.
+func (p *Queue[T]) PopAll() []T {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.popN(len(p.content))
+}
+
+// PeekN gets the first 'n' elements of the queue and returns them to the caller.
+// If the queue holds fewer than 'n' elements an 'Underflow error' is returned,
+// a negative 'n' returns a 'Negative count error'.
+func (p *Queue[T]) PeekN(n int) ([]T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if n < 0 {
+		return nil, &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return nil, &UnderflowError{}
+	}
+
+	return p.peekN(n), nil
+}
+
+// PeekUpToN gets at most 'n' elements of the queue and returns them to the
+// caller together with the number of elements returned.
+func (p *Queue[T]) PeekUpToN(n int) ([]T, int) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	values := p.peekN(p.available(n))
+
+	return values, len(values)
+}
+
+// DropN drops the first 'n' elements of the queue.
+// The operation is atomic: if the queue holds fewer than 'n' elements
+// an 'Underflow error' is returned and the queue is left untouched.
+// A negative 'n' returns a 'Negative count error'.
+func (p *Queue[T]) DropN(n int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n < 0 {
+		return &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return &UnderflowError{}
+	}
+
+	p.dropN(n, event.Dropped)
+
+	return nil
+}
+
+// DropUpToN drops at most 'n' elements of the queue and returns
+// the number of elements dropped.
+func (p *Queue[T]) DropUpToN(n int) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n = p.available(n)
+
+	p.dropN(n, event.Dropped)
+
+	return n
+}
+
+// append adds the provided values to the end of the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Queue[T]) append(values ...T) {
+	p.content = append(p.content, values...)
+	p.notify()
+	p.publish(event.Pushed, values...)
+}
+
+// available limits 'n' to the range of elements held by the queue.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Queue[T]) available(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	if n > len(p.content) {
+		return len(p.content)
+	}
+
+	return n
+}
+
+// peekN returns a copy of the first 'n' elements.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Queue[T]) peekN(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+
+	values := make([]T, n)
+
+	copy(values, p.content[:n])
+
+	return values
+}
+
+// popN removes the first 'n' elements and returns them.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Queue[T]) popN(n int) []T {
+	values := p.peekN(n)
+
+	p.dropN(n, event.Popped)
+
+	return values
+}
+
+// dropN removes the first 'n' elements and reports them
+// to the subscribers using the provided event kind.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Queue[T]) dropN(n int, kind event.Kind) {
+	if n <= 0 {
+		return
+	}
+
+	p.publish(kind, p.content[:n]...)
+
+	p.content = p.content[n:]
+	p.notify()
+}
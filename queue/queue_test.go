@@ -1,12 +1,74 @@
 package queue_test
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/piccobit/generics/queue"
 )
 
+// fakeClock is a manually advanced clock, its timers fire when
+// the clock is advanced past their due time.
+type fakeClock struct {
+	now    time.Time
+	timers []*fakeTimer
+	mutex  sync.Mutex
+}
+
+type fakeTimer struct {
+	due time.Time
+	c   chan time.Time
+}
+
+func (p *fakeClock) Now() time.Time {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.now
+}
+
+func (p *fakeClock) NewTimer(d time.Duration) queue.Timer {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	timer := fakeTimer{due: p.now.Add(d), c: make(chan time.Time, 1)}
+	p.timers = append(p.timers, &timer)
+
+	return &timer
+}
+
+// Advance moves the clock forward and fires all timers which became due.
+func (p *fakeClock) Advance(d time.Duration) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.now = p.now.Add(d)
+
+	pending := p.timers[:0]
+
+	for _, timer := range p.timers {
+		if timer.due.After(p.now) {
+			pending = append(pending, timer)
+		} else {
+			timer.c <- p.now
+		}
+	}
+
+	p.timers = pending
+}
+
+func (p *fakeTimer) C() <-chan time.Time {
+	return p.c
+}
+
+func (p *fakeTimer) Stop() bool {
+	return true
+}
+
 func ExampleQueue_Push() {
 	var err error
 
@@ -65,3 +127,552 @@ func ExampleQueue_Drop() {
 	// Output:
 	// Content: [World]
 }
+
+func ExampleQueue_PopN() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3, 4, 5)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, err := myIntQueue.PopN(3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PopN: %v\n", values)
+
+	_, err = myIntQueue.PopN(3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntQueue)
+	// Output:
+	// PopN: [1 2 3]
+	// ERROR: Underflow error
+	// Content: [4,5]
+}
+
+func ExampleQueue_PopN_negative() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntQueue.PopN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntQueue.PeekN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntQueue.DropN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntQueue)
+	// Output:
+	// ERROR: Negative count error
+	// ERROR: Negative count error
+	// ERROR: Negative count error
+	// Content: [1,2,3]
+}
+
+func ExampleQueue_PopUpToN() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, n := myIntQueue.PopUpToN(5)
+
+	fmt.Printf("PopUpToN: %v (%d)\n", values, n)
+	fmt.Printf("Length: %d\n", myIntQueue.Length())
+	// Output:
+	// PopUpToN: [1 2 3] (3)
+	// Length: 0
+}
+
+func ExampleQueue_PeekN() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, err := myIntQueue.PeekN(2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PeekN: %v\n", values)
+	fmt.Printf("Content: %v\n", myIntQueue)
+	// Output:
+	// PeekN: [1 2]
+	// Content: [1,2,3]
+}
+
+func ExampleQueue_DropN() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntQueue.DropN(2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntQueue)
+	fmt.Printf("DropUpToN: %d\n", myIntQueue.DropUpToN(2))
+	fmt.Printf("Content: %v\n", myIntQueue)
+	// Output:
+	// Content: [3]
+	// DropUpToN: 1
+	// Content: []
+}
+
+func ExampleQueue_PopAll() {
+	var err error
+
+	myIntQueue := queue.New[int](0)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PopAll: %v\n", myIntQueue.PopAll())
+	fmt.Printf("Length: %d\n", myIntQueue.Length())
+	// Output:
+	// PopAll: [1 2 3]
+	// Length: 0
+}
+
+func ExampleQueue_Offer_acceptPartial() {
+	myIntQueue := queue.New[int](3, queue.WithOverflowPolicy(queue.AcceptPartial))
+
+	n, err := myIntQueue.Offer(1, 2, 3, 4, 5)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Accepted: %d\n", n)
+	fmt.Printf("Content: %v\n", myIntQueue)
+	fmt.Printf("Truncated: %d\n", myIntQueue.OverflowStats().Truncated)
+	// Output:
+	// ERROR: Overflow error
+	// Accepted: 3
+	// Content: [1,2,3]
+	// Truncated: 2
+}
+
+func ExampleQueue_Offer_dropOldest() {
+	var err error
+
+	myIntQueue := queue.New[int](3, queue.WithOverflowPolicy(queue.DropOldest))
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	n, err := myIntQueue.Offer(4, 5)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Accepted: %d\n", n)
+	fmt.Printf("Content: %v\n", myIntQueue)
+	fmt.Printf("DroppedOldest: %d\n", myIntQueue.OverflowStats().DroppedOldest)
+	// Output:
+	// Accepted: 2
+	// Content: [3,4,5]
+	// DroppedOldest: 2
+}
+
+func ExampleQueue_Offer_dropNewest() {
+	var err error
+
+	myIntQueue := queue.New[int](3, queue.WithOverflowPolicy(queue.DropNewest))
+
+	err = myIntQueue.Push(1, 2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	n, err := myIntQueue.Offer(3, 4, 5)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Accepted: %d\n", n)
+	fmt.Printf("Content: %v\n", myIntQueue)
+	fmt.Printf("DroppedNewest: %d\n", myIntQueue.OverflowStats().DroppedNewest)
+	// Output:
+	// Accepted: 1
+	// Content: [1,2,3]
+	// DroppedNewest: 2
+}
+
+func ExampleQueue_overflow() {
+	var err error
+
+	myIntQueue := queue.New[int](3)
+
+	err = myIntQueue.Push(1, 2, 3, 4)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntQueue)
+	fmt.Printf("Rejected: %d\n", myIntQueue.OverflowStats().Rejected)
+	// Output:
+	// ERROR: Overflow error
+	// Content: []
+	// Rejected: 4
+}
+
+func ExampleQueue_Chan() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	myIntQueue := queue.New[int](2)
+
+	sink := myIntQueue.Sink(ctx)
+
+	go func() {
+		for i := 1; i <= 5; i++ {
+			sink <- i
+		}
+
+		close(sink)
+	}()
+
+	out := myIntQueue.Chan(ctx)
+
+	for i := 1; i <= 5; i++ {
+		fmt.Printf("Received: %d\n", <-out)
+	}
+	// Output:
+	// Received: 1
+	// Received: 2
+	// Received: 3
+	// Received: 4
+	// Received: 5
+}
+
+func ExampleFromChan() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan string)
+
+	myStringQueue := queue.FromChan(ctx, in)
+
+	go func() {
+		in <- "Hello"
+		in <- "World"
+
+		close(in)
+	}()
+
+	out := myStringQueue.Chan(ctx)
+
+	fmt.Printf("Received: %s\n", <-out)
+	fmt.Printf("Received: %s\n", <-out)
+	// Output:
+	// Received: Hello
+	// Received: World
+}
+
+func ExampleReliable_Reserve() {
+	var err error
+
+	myStringQueue := queue.NewReliable[string](0, time.Minute, 0)
+
+	err = myStringQueue.Push("Hello", "World")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, token, err := myStringQueue.Reserve(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Reserve: %s\n", value)
+	fmt.Printf("Length: %d, InFlight: %d\n", myStringQueue.Length(), myStringQueue.InFlight())
+
+	err = myStringQueue.Ack(token)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.Ack(token)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Length: %d, InFlight: %d\n", myStringQueue.Length(), myStringQueue.InFlight())
+	// Output:
+	// Reserve: Hello
+	// Length: 1, InFlight: 1
+	// ERROR: Unknown token error
+	// Length: 1, InFlight: 0
+}
+
+func ExampleReliable_Nack() {
+	var err error
+
+	myStringQueue := queue.NewReliable[string](0, time.Minute, 2)
+
+	err = myStringQueue.Push("Hello", "World")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for i := 0; i < 2; i++ {
+		value, token, err := myStringQueue.Reserve(context.Background())
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Reserve: %s\n", value)
+
+		err = myStringQueue.Nack(token)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringQueue.DeadLetters())
+	fmt.Printf("Length: %d\n", myStringQueue.Length())
+	// Output:
+	// Reserve: Hello
+	// Reserve: Hello
+	// Content: [Hello]
+	// Length: 1
+}
+
+func ExampleReliable_lease() {
+	var err error
+
+	myStringQueue := queue.NewReliable[string](0, 10*time.Millisecond, 0)
+
+	err = myStringQueue.Push("Hello")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, token, err := myStringQueue.Reserve(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Reserve: %s\n", value)
+
+	// The lease expires while waiting, so the value is delivered again.
+	value, _, err = myStringQueue.Reserve(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Reserve: %s\n", value)
+
+	err = myStringQueue.Ack(token)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Reserve: Hello
+	// Reserve: Hello
+	// ERROR: Unknown token error
+}
+
+func ExampleWithClock() {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+
+	myStringQueue := queue.NewReliable[string](0, time.Minute, 0, queue.WithClock(clock))
+
+	err := myStringQueue.Push("Hello")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, token, err := myStringQueue.Reserve(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Length: %d, InFlight: %d\n", myStringQueue.Length(), myStringQueue.InFlight())
+
+	// The lease expires when the clock passes it.
+	clock.Advance(2 * time.Minute)
+
+	fmt.Printf("Length: %d, InFlight: %d\n", myStringQueue.Length(), myStringQueue.InFlight())
+
+	err = myStringQueue.Ack(token)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Length: 0, InFlight: 1
+	// Length: 1, InFlight: 0
+	// ERROR: Unknown token error
+}
+
+func ExampleReliable_Push_overflow() {
+	myStringQueue := queue.NewReliable[string](2, time.Minute, 0)
+
+	err := myStringQueue.Push("Hello", "World")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, token, err := myStringQueue.Reserve(context.Background())
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	// The reserved element still counts, so it fits when it is released.
+	err = myStringQueue.Push("Again")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	err = myStringQueue.Nack(token)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Length: %d, InFlight: %d\n", myStringQueue.Length(), myStringQueue.InFlight())
+	// Output:
+	// ERROR: Overflow error
+	// Length: 2, InFlight: 0
+}
+
+func ExampleWindow_Min() {
+	myIntWindow := queue.NewWindow[int](3, queue.WithOverflowPolicy(queue.DropOldest))
+
+	for _, value := range []int{4, 2, 12, 3, 8, 1, 5} {
+		err := myIntWindow.Push(value)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		minValue, _ := myIntWindow.Min()
+		maxValue, _ := myIntWindow.Max()
+
+		fmt.Printf("Content: %v, Min: %d, Max: %d\n", myIntWindow, minValue, maxValue)
+	}
+	// Output:
+	// Content: [4], Min: 4, Max: 4
+	// Content: [4,2], Min: 2, Max: 4
+	// Content: [4,2,12], Min: 2, Max: 12
+	// Content: [2,12,3], Min: 2, Max: 12
+	// Content: [12,3,8], Min: 3, Max: 12
+	// Content: [3,8,1], Min: 1, Max: 8
+	// Content: [8,1,5], Min: 1, Max: 8
+}
+
+func ExampleWindow_Min_nan() {
+	myFloatWindow := queue.NewWindow[float64](2, queue.WithOverflowPolicy(queue.DropOldest))
+
+	// A NaN isn't equal to itself, but it still leaves the window.
+	for _, value := range []float64{5, math.NaN(), 7, 3, 9} {
+		err := myFloatWindow.Push(value)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		minValue, _ := myFloatWindow.Min()
+		maxValue, _ := myFloatWindow.Max()
+
+		fmt.Printf("Content: %v, Min: %v, Max: %v\n", myFloatWindow, minValue, maxValue)
+	}
+	// Output:
+	// Content: [5], Min: 5, Max: 5
+	// Content: [5,NaN], Min: 5, Max: 5
+	// Content: [NaN,7], Min: NaN, Max: NaN
+	// Content: [7,3], Min: 3, Max: 7
+	// Content: [3,9], Min: 3, Max: 9
+}
+
+func ExampleWindow_Pop() {
+	var err error
+
+	myIntWindow := queue.NewWindow[int](0)
+
+	err = myIntWindow.Push(3, 1, 3, 1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for myIntWindow.Length() > 0 {
+		minValue, _ := myIntWindow.Min()
+		maxValue, _ := myIntWindow.Max()
+
+		fmt.Printf("Content: %v, Min: %d, Max: %d\n", myIntWindow, minValue, maxValue)
+
+		_, _ = myIntWindow.Pop()
+	}
+	// Output:
+	// Content: [3,1,3,1], Min: 1, Max: 3
+	// Content: [1,3,1], Min: 1, Max: 3
+	// Content: [3,1], Min: 1, Max: 3
+	// Content: [1], Min: 1, Max: 1
+}
+
+func ExampleQueue_Subscribe() {
+	var err error
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	myIntQueue := queue.New[int](2, queue.WithOverflowPolicy(queue.DropOldest))
+
+	events := myIntQueue.Subscribe(ctx)
+
+	err = myIntQueue.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntQueue.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s: %d\n", e.Kind, e.Value)
+	}
+	// Output:
+	// Pushed: 2
+	// Pushed: 3
+	// Popped: 2
+}
@@ -0,0 +1,322 @@
+package queue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time and timers to the reliable queue.
+// It allows to replace the wall clock, e.g. in tests.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is the subset of the 'time.Timer' functionality used
+// by the reliable queue.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemClock struct{}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return &systemTimer{timer: time.NewTimer(d)}
+}
+
+func (p *systemTimer) C() <-chan time.Time {
+	return p.timer.C
+}
+
+func (p *systemTimer) Stop() bool {
+	return p.timer.Stop()
+}
+
+// Token identifies a reserved element of a reliable queue.
+type Token uint64
+
+type envelope[T any] struct {
+	value    T
+	attempts int
+}
+
+type lease[T any] struct {
+	envelope[T]
+	expires time.Time
+}
+
+// Reliable is a FIFO queue whose elements have to be acknowledged after they
+// have been processed. A reserved element which is not acknowledged in time
+// or which is explicitly rejected becomes available again. Elements which
+// failed too often are moved to a dead-letter queue.
+// Expired leases are released when the queue is accessed, a Reserve waiting
+// for an element is woken up when the next lease expires.
+type Reliable[T any] struct {
+	pending     *Queue[envelope[T]]
+	inFlight    map[Token]lease[T]
+	deadLetters *Queue[T]
+	clock       Clock
+	maxSize     int
+	leaseTime   time.Duration
+	maxAttempts int
+	nextToken   Token
+	mutex       sync.Mutex
+}
+
+type UnknownTokenError struct{}
+
+func (e *UnknownTokenError) Error() string {
+	return "Unknown token error"
+}
+
+// ReliableOption configures optional behaviour of a reliable queue.
+type ReliableOption func(*reliableOptions)
+
+type reliableOptions struct {
+	clock Clock
+}
+
+// WithClock sets the clock used for the leases of the reliable queue.
+// The default clock is the wall clock.
+func WithClock(clock Clock) ReliableOption {
+	return func(o *reliableOptions) {
+		o.clock = clock
+	}
+}
+
+// NewReliable returns the pointer to a new reliable queue.
+// The 'maxSize' parameter allows to specify a maximum size for the
+// elements waiting to be reserved and the reserved ones, so released
+// elements always fit into the queue. Setting this to 0 allows the queue
+// to grow infinitely. The 'leaseTime' parameter defines how long a reserved
+// element stays invisible before it is delivered again. The 'maxAttempts'
+// parameter defines after how many failed deliveries an element is moved to
+// the dead-letter queue. Setting this to 0 allows infinite attempts.
+// The optional 'opts' parameters allow to replace the clock.
+func NewReliable[T any](maxSize int, leaseTime time.Duration, maxAttempts int, opts ...ReliableOption) *Reliable[T] {
+	o := reliableOptions{clock: systemClock{}}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	queue := Reliable[T]{
+		pending:     New[envelope[T]](0),
+		inFlight:    make(map[Token]lease[T]),
+		deadLetters: New[T](0),
+		clock:       o.clock,
+		maxSize:     maxSize,
+		leaseTime:   leaseTime,
+		maxAttempts: maxAttempts,
+	}
+
+	return &queue
+}
+
+// Push pushes the given arguments on the provided queue.
+// An overflow error is returned in case the queue is
+// limited in its size and the push would overflow the queue.
+func (p *Reliable[T]) Push(args ...T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize > 0 && p.pending.Length()+len(p.inFlight)+len(args) > p.maxSize {
+		return &OverflowError{}
+	}
+
+	envelopes := make([]envelope[T], len(args))
+
+	for i, arg := range args {
+		envelopes[i].value = arg
+	}
+
+	return p.pending.Push(envelopes...)
+}
+
+// Reserve takes the first available element of the queue and returns it to
+// the caller together with a token which has to be passed to Ack or Nack.
+// The element becomes available again if it is not acknowledged before its
+// lease expires. If no element is available, Reserve blocks until one is
+// pushed or released or the context is cancelled, in which case the error
+// of the context is returned.
+func (p *Reliable[T]) Reserve(ctx context.Context) (T, Token, error) {
+	for {
+		p.mutex.Lock()
+
+		now := p.clock.Now()
+
+		p.expire(now)
+
+		changed := p.pending.wait()
+
+		element, err := p.pending.Pop()
+		if err == nil {
+			element.attempts++
+
+			token := p.nextToken
+			p.nextToken++
+
+			p.inFlight[token] = lease[T]{
+				envelope: element,
+				expires:  now.Add(p.leaseTime),
+			}
+
+			p.mutex.Unlock()
+
+			return element.value, token, nil
+		}
+
+		next, ok := p.nextExpiry()
+
+		p.mutex.Unlock()
+
+		var timer Timer
+		var expired <-chan time.Time
+
+		if ok {
+			timer = p.clock.NewTimer(next.Sub(now))
+			expired = timer.C()
+		}
+
+		select {
+		case <-changed:
+		case <-expired:
+		case <-ctx.Done():
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if ctx.Err() != nil {
+			var ret T
+			return ret, 0, ctx.Err()
+		}
+	}
+}
+
+// Ack acknowledges the processing of the reserved element identified
+// by the provided token and removes it finally from the queue.
+// An unknown token error is returned if the token was already
+// acknowledged or rejected or if its lease has expired.
+func (p *Reliable[T]) Ack(token Token) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.expire(p.clock.Now())
+
+	if _, ok := p.inFlight[token]; !ok {
+		return &UnknownTokenError{}
+	}
+
+	delete(p.inFlight, token)
+
+	return nil
+}
+
+// Nack rejects the reserved element identified by the provided token, so that
+// it becomes available again or is moved to the dead-letter queue if it has
+// reached the maximum number of attempts.
+// An unknown token error is returned if the token was already
+// acknowledged or rejected or if its lease has expired.
+func (p *Reliable[T]) Nack(token Token) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.expire(p.clock.Now())
+
+	reserved, ok := p.inFlight[token]
+	if !ok {
+		return &UnknownTokenError{}
+	}
+
+	delete(p.inFlight, token)
+
+	p.release(reserved.envelope)
+
+	return nil
+}
+
+// Length returns the number of elements waiting to be reserved.
+func (p *Reliable[T]) Length() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.expire(p.clock.Now())
+
+	return p.pending.Length()
+}
+
+// InFlight returns the number of reserved elements which
+// have not been acknowledged yet.
+func (p *Reliable[T]) InFlight() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.expire(p.clock.Now())
+
+	return len(p.inFlight)
+}
+
+// DeadLetters returns the queue holding the elements which have
+// reached the maximum number of attempts.
+func (p *Reliable[T]) DeadLetters() *Queue[T] {
+	return p.deadLetters
+}
+
+// expire releases all reserved elements whose lease has expired.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Reliable[T]) expire(now time.Time) {
+	for token, reserved := range p.inFlight {
+		if reserved.expires.After(now) {
+			continue
+		}
+
+		delete(p.inFlight, token)
+
+		p.release(reserved.envelope)
+	}
+}
+
+// release makes the provided element available again or moves it to
+// the dead-letter queue if it has reached the maximum number of attempts.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Reliable[T]) release(element envelope[T]) {
+	if p.maxAttempts > 0 && element.attempts >= p.maxAttempts {
+		_ = p.deadLetters.Push(element.value)
+
+		return
+	}
+
+	p.pending.requeue(element)
+}
+
+// nextExpiry returns the earliest expiry time of all reserved elements.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Reliable[T]) nextExpiry() (time.Time, bool) {
+	var next time.Time
+
+	found := false
+
+	for _, reserved := range p.inFlight {
+		if !found || reserved.expires.Before(next) {
+			next = reserved.expires
+			found = true
+		}
+	}
+
+	return next, found
+}
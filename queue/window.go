@@ -0,0 +1,241 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/piccobit/generics/constraints"
+)
+
+// Window is a FIFO queue which keeps track of the minimum and the maximum
+// of its elements using monotonic queues, so both are available in amortized
+// constant time. Combined with the 'DropOldest' overflow policy the window
+// slides over a stream of values.
+type Window[T constraints.Ordered] struct {
+	content []T
+	mins    []windowItem[T]
+	maxs    []windowItem[T]
+	head    uint64
+	maxSize int
+	policy  OverflowPolicy
+	stats   OverflowStats
+	mutex   sync.RWMutex
+}
+
+// windowItem is an element of the monotonic queues. The sequence number
+// identifies the element, as values like NaN are not equal to themselves.
+type windowItem[T constraints.Ordered] struct {
+	seq   uint64
+	value T
+}
+
+// NewWindow returns the pointer to a new min/max tracking queue.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the queue. Setting this to 0
+// allows the queue to grow infinitely.
+// The optional 'opts' parameters allow to change
+// the behaviour of the queue, e.g. its overflow policy.
+func NewWindow[T constraints.Ordered](maxSize int, opts ...Option) *Window[T] {
+	o := options{}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	window := Window[T]{
+		maxSize: maxSize,
+		policy:  o.policy,
+	}
+
+	return &window
+}
+
+// Push pushes the given arguments on the provided queue.
+// An overflow error is returned in case the queue is
+// limited in its size and the push would overflow the queue.
+// How the arguments are handled in case of an overflow
+// depends on the overflow policy of the queue.
+func (p *Window[T]) Push(args ...T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	drop, first, last, err := p.policy.apply(p.maxSize, len(p.content), len(args), &p.stats)
+
+	for i := 0; i < drop; i++ {
+		p.pop()
+	}
+
+	for _, arg := range args[first:last] {
+		p.push(arg)
+	}
+
+	return err
+}
+
+// Pop pops the first element of the queue and returns it to the caller.
+// If the queue is empty an 'Underflow error' is returned.
+func (p *Window[T]) Pop() (T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.pop(), nil
+}
+
+// Drop drops the first element of the queue.
+// An underflow error is returned in case the queue is
+// already empty.
+func (p *Window[T]) Drop() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.content) <= 0 {
+		return &UnderflowError{}
+	}
+
+	p.pop()
+
+	return nil
+}
+
+// Peek gets the first element of the queue and returns it to the caller.
+// If the queue is empty an 'Underflow error' is returned.
+func (p *Window[T]) Peek() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.content[0], nil
+}
+
+// Min returns the smallest element of the queue.
+// If the queue is empty an 'Underflow error' is returned.
+func (p *Window[T]) Min() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.mins) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.mins[0].value, nil
+}
+
+// Max returns the largest element of the queue.
+// If the queue is empty an 'Underflow error' is returned.
+func (p *Window[T]) Max() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.maxs) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.maxs[0].value, nil
+}
+
+// Length returns the number of queue elements.
+func (p *Window[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.content)
+}
+
+// OverflowStats returns the number of elements which were rejected
+// or dropped because of an overflow of the queue.
+func (p *Window[T]) OverflowStats() OverflowStats {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.stats
+}
+
+// String implements the Stringer interface to provide a
+// textual representation of the queue content.
+func (p *Window[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	for i, value := range p.content {
+		if i > 0 {
+			str.WriteString(",")
+		}
+
+		_, _ = fmt.Fprintf(&str, "%v", value)
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// GetQueue returns the queue content so that it can be
+// used in a 'for range' loop.
+func (p *Window[T]) GetQueue() []T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make([]T, len(p.content))
+
+	copy(content, p.content)
+
+	return content
+}
+
+// push appends the provided value and updates the monotonic queues.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Window[T]) push(value T) {
+	item := windowItem[T]{seq: p.head + uint64(len(p.content)), value: value}
+
+	p.content = append(p.content, value)
+
+	for len(p.mins) > 0 && p.mins[len(p.mins)-1].value > value {
+		p.mins = p.mins[:len(p.mins)-1]
+	}
+
+	p.mins = append(p.mins, item)
+
+	for len(p.maxs) > 0 && p.maxs[len(p.maxs)-1].value < value {
+		p.maxs = p.maxs[:len(p.maxs)-1]
+	}
+
+	p.maxs = append(p.maxs, item)
+}
+
+// pop removes the first value and updates the monotonic queues.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Window[T]) pop() T {
+	value := p.content[0]
+
+	p.content = p.content[1:]
+
+	if p.mins[0].seq == p.head {
+		p.mins = p.mins[1:]
+	}
+
+	if p.maxs[0].seq == p.head {
+		p.maxs = p.maxs[1:]
+	}
+
+	p.head++
+
+	return value
+}
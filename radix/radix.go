@@ -0,0 +1,248 @@
+/*
+Package radix is a simple implementation of a radix tree holding a set of string keys.
+Keys sharing a common prefix share the nodes of the prefix, so all keys with a given
+prefix can be found without looking at the other keys.
+*/
+package radix
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+type node struct {
+	prefix   string
+	children []*node
+	leaf     bool
+}
+
+type Tree struct {
+	root   node
+	length int
+	mutex  sync.RWMutex
+}
+
+// New returns the pointer to a new, empty radix tree.
+func New() *Tree {
+	tree := Tree{}
+
+	return &tree
+}
+
+// Insert adds the provided key to the tree.
+// If the key was already part of the tree 'false' is returned.
+func (p *Tree) Insert(key string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n := &p.root
+
+	for {
+		if key == "" {
+			if n.leaf {
+				return false
+			}
+
+			n.leaf = true
+			p.length++
+
+			return true
+		}
+
+		i, child := n.child(key[0])
+		if child == nil {
+			n.addChild(&node{prefix: key, leaf: true})
+			p.length++
+
+			return true
+		}
+
+		common := commonPrefix(child.prefix, key)
+
+		if common == len(child.prefix) {
+			n = child
+			key = key[common:]
+
+			continue
+		}
+
+		split := &node{prefix: child.prefix[:common]}
+		child.prefix = child.prefix[common:]
+		split.children = []*node{child}
+		n.children[i] = split
+
+		n = split
+		key = key[common:]
+	}
+}
+
+// Delete removes the provided key from the tree.
+// If the key wasn't part of the tree 'false' is returned.
+func (p *Tree) Delete(key string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if key == "" {
+		if !p.root.leaf {
+			return false
+		}
+
+		p.root.leaf = false
+		p.length--
+
+		return true
+	}
+
+	if !deleteKey(&p.root, key) {
+		return false
+	}
+
+	p.length--
+
+	return true
+}
+
+// Contains checks if the tree contains the provided key.
+func (p *Tree) Contains(key string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	n := &p.root
+
+	for key != "" {
+		_, child := n.child(key[0])
+		if child == nil || !strings.HasPrefix(key, child.prefix) {
+			return false
+		}
+
+		n = child
+		key = key[len(child.prefix):]
+	}
+
+	return n.leaf
+}
+
+// WalkPrefix calls the provided function for every key with the provided
+// prefix in lexical order, until the function returns 'false'.
+// The function must not modify the tree.
+func (p *Tree) WalkPrefix(prefix string, fn func(key string) bool) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	n := &p.root
+	path := ""
+
+	for prefix != "" {
+		_, child := n.child(prefix[0])
+		if child == nil {
+			return
+		}
+
+		switch {
+		case strings.HasPrefix(prefix, child.prefix):
+			prefix = prefix[len(child.prefix):]
+		case strings.HasPrefix(child.prefix, prefix):
+			prefix = ""
+		default:
+			return
+		}
+
+		path += child.prefix
+		n = child
+	}
+
+	walk(n, path, fn)
+}
+
+// Len returns the number of keys in the tree.
+func (p *Tree) Len() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.length
+}
+
+// child returns the index & the child starting with the provided byte.
+func (n *node) child(b byte) (int, *node) {
+	i := sort.Search(len(n.children), func(i int) bool {
+		return n.children[i].prefix[0] >= b
+	})
+
+	if i < len(n.children) && n.children[i].prefix[0] == b {
+		return i, n.children[i]
+	}
+
+	return i, nil
+}
+
+// addChild adds the provided child keeping the children sorted.
+func (n *node) addChild(child *node) {
+	i, _ := n.child(child.prefix[0])
+
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+}
+
+// deleteKey removes the provided key below the provided node and
+// merges the nodes which are no longer needed.
+func deleteKey(parent *node, key string) bool {
+	i, child := parent.child(key[0])
+	if child == nil || !strings.HasPrefix(key, child.prefix) {
+		return false
+	}
+
+	rest := key[len(child.prefix):]
+
+	if rest == "" {
+		if !child.leaf {
+			return false
+		}
+
+		child.leaf = false
+	} else if !deleteKey(child, rest) {
+		return false
+	}
+
+	if child.leaf {
+		return true
+	}
+
+	switch len(child.children) {
+	case 0:
+		parent.children = append(parent.children[:i], parent.children[i+1:]...)
+	case 1:
+		grandchild := child.children[0]
+		grandchild.prefix = child.prefix + grandchild.prefix
+		parent.children[i] = grandchild
+	}
+
+	return true
+}
+
+// walk calls the provided function for all keys below the provided node.
+func walk(n *node, path string, fn func(key string) bool) bool {
+	if n.leaf && !fn(path) {
+		return false
+	}
+
+	for _, child := range n.children {
+		if !walk(child, path+child.prefix, fn) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// commonPrefix returns the length of the common prefix of both strings.
+func commonPrefix(a string, b string) int {
+	i := 0
+
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+
+	return i
+}
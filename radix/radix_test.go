@@ -0,0 +1,34 @@
+package radix_test
+
+import (
+	"fmt"
+
+	"github.com/piccobit/generics/radix"
+)
+
+func ExampleTree_WalkPrefix() {
+	tree := radix.New()
+
+	for _, key := range []string{"tenant:42:user:7", "tenant:42:user:8", "tenant:4:user:1", "tenant:42", "other"} {
+		tree.Insert(key)
+	}
+
+	tree.WalkPrefix("tenant:42", func(key string) bool {
+		fmt.Println(key)
+
+		return true
+	})
+
+	tree.Delete("tenant:42:user:7")
+
+	fmt.Printf("Length: %d\n", tree.Len())
+	fmt.Printf("Contains tenant:42:user:7: %v\n", tree.Contains("tenant:42:user:7"))
+	fmt.Printf("Contains tenant:42:user:8: %v\n", tree.Contains("tenant:42:user:8"))
+	// Output:
+	// tenant:42
+	// tenant:42:user:7
+	// tenant:42:user:8
+	// Length: 4
+	// Contains tenant:42:user:7: false
+	// Contains tenant:42:user:8: true
+}
@@ -0,0 +1,296 @@
+/*
+Package slru is a simple generic implementation of a segmented LRU (Least Recently Used) cache.
+New entries are added to a probationary segment and are only promoted to a protected segment
+when they are accessed again. Entries are evicted from the probationary segment first, so a
+single sequential scan does not wipe out the frequently used entries.
+*/
+package slru
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type item[T any] struct {
+	id        string
+	value     T
+	protected bool
+}
+
+type SLRUCache[T any] struct {
+	probation     *list.List
+	protected     *list.List
+	index         map[string]*list.Element
+	maxSize       int
+	maxProtected  int
+	hitsCounter   uint
+	missedCounter uint
+	stats         SegmentStats
+	mutex         sync.RWMutex
+}
+
+// SegmentStats holds the number of entries moved between the
+// segments of the cache and the number of evicted entries.
+type SegmentStats struct {
+	Promotions uint
+	Demotions  uint
+	Evictions  uint
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+type IDInterface interface {
+	ID() string
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// Option configures optional behaviour of a segmented LRU cache.
+type Option func(*options)
+
+type options struct {
+	protectedRatio float64
+}
+
+// WithProtectedRatio sets the share of the cache used for the
+// protected segment. The default ratio is 0.8.
+func WithProtectedRatio(ratio float64) Option {
+	return func(o *options) {
+		o.protectedRatio = ratio
+	}
+}
+
+// New returns the pointer to a new segmented LRU cache.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the cache.
+// The optional 'opts' parameters allow to change
+// the sizes of the segments.
+func New[T any](maxSize int, opts ...Option) *SLRUCache[T] {
+	o := options{protectedRatio: 0.8}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cache := SLRUCache[T]{
+		probation:    list.New(),
+		protected:    list.New(),
+		index:        make(map[string]*list.Element),
+		maxSize:      maxSize,
+		maxProtected: int(float64(maxSize) * o.protectedRatio),
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided ID.
+// If the ID doesn't exist 'false' is returned.
+// A hit on the probationary segment promotes the
+// entry to the protected segment.
+func (p *SLRUCache[T]) Get(id string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	element, ok := p.index[id]
+	if !ok {
+		var dummy T
+
+		p.missedCounter++
+
+		return dummy, false
+	}
+
+	p.hitsCounter++
+
+	p.touch(element)
+
+	return element.Value.(*item[T]).value, true
+}
+
+// Contains checks if the cache contains an element with
+// the provided ID.
+func (p *SLRUCache[T]) Contains(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	_, ok := p.index[id]
+
+	return ok
+}
+
+// String implements the Stringer interface.
+// The entries of the probationary segment are listed before the
+// entries of the protected segment, each from the least to the
+// most recently used.
+func (p *SLRUCache[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	followingItems := false
+
+	for _, l := range []*list.List{p.probation, p.protected} {
+		for element := l.Back(); element != nil; element = element.Prev() {
+			if followingItems {
+				str.WriteString(",")
+			} else {
+				followingItems = true
+			}
+
+			_, _ = fmt.Fprintf(&str, "%v", element.Value.(*item[T]).value)
+		}
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// AddByID adds the provided argument with the provided ID to the cache.
+// New items are added to the probationary segment. If the cache has already
+// reached its maximum size, the least recently used entry of the probationary
+// segment is dropped to make place for the new one.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *SLRUCache[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize <= 0 {
+		return &OverflowError{}
+	}
+
+	if element, ok := p.index[id]; ok {
+		element.Value.(*item[T]).value = arg
+
+		p.touch(element)
+
+		return nil
+	}
+
+	if p.probation.Len()+p.protected.Len() >= p.maxSize {
+		victims := p.probation
+		if victims.Len() == 0 {
+			victims = p.protected
+		}
+
+		cacheItem := victims.Remove(victims.Back()).(*item[T])
+
+		delete(p.index, cacheItem.id)
+
+		p.stats.Evictions++
+	}
+
+	p.index[id] = p.probation.PushFront(&item[T]{id: id, value: arg})
+
+	return nil
+}
+
+// Add adds the provided argument to the cache.
+// The ID used is either provided using the ID interface or generated internally.
+// New items are added to the probationary segment. If the cache has already
+// reached its maximum size, the least recently used entry of the probationary
+// segment is dropped to make place for the new one.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *SLRUCache[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Stats returns the statistics of the provided cache,
+// the hits & the misses.
+func (p *SLRUCache[T]) Stats() (uint, uint) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.hitsCounter, p.missedCounter
+}
+
+// SegmentStats returns the number of promotions to and demotions from
+// the protected segment and the number of evicted entries.
+func (p *SLRUCache[T]) SegmentStats() SegmentStats {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.stats
+}
+
+// Length returns the number of cached entries.
+func (p *SLRUCache[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.index)
+}
+
+// GetCache returns the cache content so that it can be
+// used in a 'for range' loop.
+func (p *SLRUCache[T]) GetCache() map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for id, element := range p.index {
+		content[id] = element.Value.(*item[T]).value
+	}
+
+	return content
+}
+
+// touch moves an accessed entry to the front of the protected segment.
+// If the protected segment overflows, its least recently used entry is
+// demoted to the front of the probationary segment.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *SLRUCache[T]) touch(element *list.Element) {
+	cacheItem := element.Value.(*item[T])
+
+	if cacheItem.protected {
+		p.protected.MoveToFront(element)
+
+		return
+	}
+
+	if p.maxProtected <= 0 {
+		p.probation.MoveToFront(element)
+
+		return
+	}
+
+	p.probation.Remove(element)
+
+	cacheItem.protected = true
+	p.index[cacheItem.id] = p.protected.PushFront(cacheItem)
+	p.stats.Promotions++
+
+	if p.protected.Len() > p.maxProtected {
+		demotedItem := p.protected.Remove(p.protected.Back()).(*item[T])
+
+		demotedItem.protected = false
+		p.index[demotedItem.id] = p.probation.PushFront(demotedItem)
+		p.stats.Demotions++
+	}
+}
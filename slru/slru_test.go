@@ -0,0 +1,66 @@
+package slru_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/piccobit/generics/slru"
+)
+
+func ExampleSLRUCache_Get() {
+	var err error
+
+	myStringCache := slru.New[string](4, slru.WithProtectedRatio(0.5))
+
+	for _, id := range []string{"a", "b", "c"} {
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		_, _ = myStringCache.Get(id)
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+
+	// A sequential scan only passes through the probationary segment.
+	for i := 1; i <= 10; i++ {
+		id := "x" + strconv.Itoa(i)
+
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+	fmt.Printf("Stats: %+v\n", myStringCache.SegmentStats())
+	// Output:
+	// Content: [a,b,c]
+	// Content: [x9,x10,b,c]
+	// Stats: {Promotions:3 Demotions:1 Evictions:9}
+}
+
+func ExampleSLRUCache_AddByID() {
+	var err error
+
+	myIntCache := slru.New[int](10)
+
+	err = myIntCache.AddByID("foo", 13)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntCache.AddByID("foo", 42)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myIntCache.Get("foo")
+	fmt.Printf("foo: %d %v\n", value, ok)
+	fmt.Printf("Length: %d\n", myIntCache.Length())
+	// Output:
+	// foo: 42 true
+	// Length: 1
+}
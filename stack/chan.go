@@ -0,0 +1,150 @@
+package stack
+
+import (
+	"context"
+	"errors"
+
+	"github.com/piccobit/generics/event"
+)
+
+// FromChan returns the pointer to a new unbounded stack which is fed with
+// the values received from the provided channel. The values are pushed
+// until the channel is closed or the context is cancelled.
+func FromChan[T any](ctx context.Context, in <-chan T) *Stack[T] {
+	stack := New[T](0)
+
+	go stack.pump(ctx, in)
+
+	return stack
+}
+
+// Chan returns a channel which delivers the elements of the stack in
+// LIFO order. The elements are handed off one at a time: the next element
+// is removed from the stack as soon as it is available and kept by the
+// channel until it is received, so while the receiver is busy 'Length()'
+// doesn't include this element and the stack keeps buffering the others.
+// Elements pushed meanwhile are delivered after the waiting element.
+// The channel is closed as soon as the context is cancelled; an element
+// waiting for its receiver at that point is pushed back on the stack, i.e.
+// on top of the elements pushed meanwhile, so it is the next one popped.
+func (p *Stack[T]) Chan(ctx context.Context) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			changed := p.wait()
+
+			value, err := p.Pop()
+			if err != nil {
+				select {
+				case <-changed:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				p.pushBack(value)
+
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Sink returns a channel whose received values are pushed on the stack.
+// If the stack is limited in its size and rejects values because of an
+// overflow, the sink stops receiving until space becomes available, so
+// senders are blocked instead of losing values. The sink stops when the
+// returned channel is closed or the context is cancelled; a value waiting
+// for space at that point is discarded. Senders must not send on the
+// channel after the context has been cancelled.
+func (p *Stack[T]) Sink(ctx context.Context) chan<- T {
+	in := make(chan T)
+
+	go p.pump(ctx, in)
+
+	return in
+}
+
+// pump pushes the values received from the provided channel on the stack
+// until the channel is closed or the context is cancelled.
+func (p *Stack[T]) pump(ctx context.Context, in <-chan T) {
+	for {
+		select {
+		case value, ok := <-in:
+			if !ok {
+				return
+			}
+
+			if !p.pushWait(ctx, value) {
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pushWait pushes the provided value on the stack and waits for free space
+// as long as the stack rejects it because of an overflow. It returns 'false'
+// if the context was cancelled before the value could be pushed.
+func (p *Stack[T]) pushWait(ctx context.Context, value T) bool {
+	var overflowError *OverflowError
+
+	for {
+		changed := p.wait()
+
+		err := p.Push(value)
+		if !errors.As(err, &overflowError) {
+			return true
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// pushBack pushes the provided value back on the stack,
+// regardless of the maximum size of the stack.
+func (p *Stack[T]) pushBack(value T) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.content = append(p.content, value)
+	p.notify()
+	p.publish(event.Pushed, value)
+}
+
+// wait returns a channel which is closed on the next modification
+// of the stack.
+func (p *Stack[T]) wait() <-chan struct{} {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.changed == nil {
+		p.changed = make(chan struct{})
+	}
+
+	return p.changed
+}
+
+// notify wakes up all goroutines waiting for a modification of the stack.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Stack[T]) notify() {
+	if p.changed != nil {
+		close(p.changed)
+		p.changed = nil
+	}
+}
@@ -0,0 +1,132 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+)
+
+type node[T any] struct {
+	value T
+	next  *node[T]
+}
+
+// Immutable is a persistent LIFO stack. Every modification returns a new
+// version of the stack which shares its elements with the version it was
+// derived from, so versions are never changed and can be passed between
+// goroutines without locking. A nil pointer is a valid empty stack.
+type Immutable[T any] struct {
+	head   *node[T]
+	length int
+}
+
+// NewImmutable returns the pointer to a new empty immutable stack.
+func NewImmutable[T any]() *Immutable[T] {
+	return &Immutable[T]{}
+}
+
+// Push returns a new version of the stack with the given arguments pushed
+// on top of it, the last argument becoming the top of the stack.
+func (p *Immutable[T]) Push(args ...T) *Immutable[T] {
+	head, length := p.top()
+
+	for _, arg := range args {
+		head = &node[T]{value: arg, next: head}
+		length++
+	}
+
+	return &Immutable[T]{head: head, length: length}
+}
+
+// Pop returns the last element of the stack together with the version
+// of the stack without this element.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *Immutable[T]) Pop() (T, *Immutable[T], error) {
+	head, length := p.top()
+
+	if head == nil {
+		var ret T
+		return ret, p, &UnderflowError{}
+	}
+
+	return head.value, &Immutable[T]{head: head.next, length: length - 1}, nil
+}
+
+// Drop returns the version of the stack without its last element.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *Immutable[T]) Drop() (*Immutable[T], error) {
+	_, stack, err := p.Pop()
+
+	return stack, err
+}
+
+// Peek gets the last element of the stack and returns it to the caller.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *Immutable[T]) Peek() (T, error) {
+	head, _ := p.top()
+
+	if head == nil {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return head.value, nil
+}
+
+// Length returns the number of stack elements.
+func (p *Immutable[T]) Length() int {
+	_, length := p.top()
+
+	return length
+}
+
+// String implements the Stringer interface to provide a
+// textual representation of the stack content.
+func (p *Immutable[T]) String() string {
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	for i, value := range p.GetStack() {
+		if i > 0 {
+			str.WriteString(",")
+		}
+
+		_, _ = fmt.Fprintf(&str, "%v", value)
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// GetStack returns the stack content so that it can be
+// used in a 'for range' loop. Like for Stack the first
+// element is the bottom of the stack.
+func (p *Immutable[T]) GetStack() []T {
+	head, length := p.top()
+
+	content := make([]T, length)
+
+	for i := length - 1; head != nil; i-- {
+		content[i] = head.value
+		head = head.next
+	}
+
+	return content
+}
+
+// Snapshot returns an immutable copy of the current stack content.
+func (p *Stack[T]) Snapshot() *Immutable[T] {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return NewImmutable[T]().Push(p.content...)
+}
+
+func (p *Immutable[T]) top() (*node[T], int) {
+	if p == nil {
+		return nil, 0
+	}
+
+	return p.head, p.length
+}
@@ -0,0 +1,191 @@
+package stack
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/piccobit/generics/constraints"
+)
+
+type extremes[T constraints.Ordered] struct {
+	value T
+	min   T
+	max   T
+}
+
+// MinMax is a LIFO stack which keeps track of the minimum
+// and the maximum of its elements.
+type MinMax[T constraints.Ordered] struct {
+	content []extremes[T]
+	maxSize int
+	mutex   sync.RWMutex
+}
+
+// NewMinMax returns the pointer to a new min/max tracking stack.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the stack. Setting this to 0
+// allows the stack to grow infinitely.
+func NewMinMax[T constraints.Ordered](maxSize int) *MinMax[T] {
+	stack := MinMax[T]{maxSize: maxSize}
+
+	return &stack
+}
+
+// Push pushes the given arguments on the provided stack.
+// An overflow error is returned in case the stack is
+// limited in its size and the push would overflow the stack.
+func (p *MinMax[T]) Push(args ...T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize > 0 && len(p.content) >= p.maxSize {
+		return &OverflowError{}
+	}
+
+	if p.maxSize > 0 && (len(p.content)+len(args)) > p.maxSize {
+		return &OverflowError{}
+	}
+
+	for _, arg := range args {
+		entry := extremes[T]{value: arg, min: arg, max: arg}
+
+		if len(p.content) > 0 {
+			top := p.content[len(p.content)-1]
+
+			if top.min < entry.min {
+				entry.min = top.min
+			}
+
+			if top.max > entry.max {
+				entry.max = top.max
+			}
+		}
+
+		p.content = append(p.content, entry)
+	}
+
+	return nil
+}
+
+// Pop pops the last element of the stack and returns it to the caller.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *MinMax[T]) Pop() (T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	value := p.content[len(p.content)-1].value
+
+	p.content = p.content[:len(p.content)-1]
+
+	return value, nil
+}
+
+// Drop drops the last element of the stack.
+// An underflow error is returned in case the stack is
+// already empty.
+func (p *MinMax[T]) Drop() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.content) <= 0 {
+		return &UnderflowError{}
+	}
+
+	p.content = p.content[:len(p.content)-1]
+
+	return nil
+}
+
+// Peek gets the last element of the stack and returns it to the caller.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *MinMax[T]) Peek() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.content[len(p.content)-1].value, nil
+}
+
+// Min returns the smallest element of the stack.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *MinMax[T]) Min() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.content[len(p.content)-1].min, nil
+}
+
+// Max returns the largest element of the stack.
+// If the stack is empty an 'Underflow error' is returned.
+func (p *MinMax[T]) Max() (T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if len(p.content) <= 0 {
+		var ret T
+		return ret, &UnderflowError{}
+	}
+
+	return p.content[len(p.content)-1].max, nil
+}
+
+// Length returns the number of stack elements.
+func (p *MinMax[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.content)
+}
+
+// String implements the Stringer interface to provide a
+// textual representation of the stack content.
+func (p *MinMax[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	for i, entry := range p.content {
+		if i > 0 {
+			str.WriteString(",")
+		}
+
+		_, _ = fmt.Fprintf(&str, "%v", entry.value)
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// GetStack returns the stack content so that it can be
+// used in a 'for range' loop.
+func (p *MinMax[T]) GetStack() []T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make([]T, len(p.content))
+
+	for i, entry := range p.content {
+		content[i] = entry.value
+	}
+
+	return content
+}
@@ -8,16 +8,21 @@ import (
 	"fmt"
 	"strings"
 	"sync"
+
+	"github.com/piccobit/generics/event"
 )
 
 type Stack[T any] struct {
 	content []T
 	maxSize int
+	changed chan struct{}
+	events  event.Broker[T]
 	mutex   sync.RWMutex
 }
 
 type UnderflowError struct{}
 type OverflowError struct{}
+type NegativeCountError struct{}
 
 func (e *UnderflowError) Error() string {
 	return "Underflow error"
@@ -27,6 +32,10 @@ func (e *OverflowError) Error() string {
 	return "Overflow error"
 }
 
+func (e *NegativeCountError) Error() string {
+	return "Negative count error"
+}
+
 // New returns the pointer to a new stack.
 // The 'maxSize' parameter allows to specify a
 // maximum size for the stack. Setting this to 0
@@ -53,6 +62,8 @@ func (p *Stack[T]) Push(args ...T) error {
 	}
 
 	p.content = append(p.content, args...)
+	p.notify()
+	p.publish(event.Pushed, args...)
 
 	return nil
 }
@@ -95,6 +106,8 @@ func (p *Stack[T]) Pop() (T, error) {
 	value := p.content[len(p.content)-1]
 
 	p.content = p.content[:len(p.content)-1]
+	p.notify()
+	p.publish(event.Popped, value)
 
 	return value, nil
 }
@@ -110,7 +123,11 @@ func (p *Stack[T]) Drop() error {
 		return &UnderflowError{}
 	}
 
+	value := p.content[len(p.content)-1]
+
 	p.content = p.content[:len(p.content)-1]
+	p.notify()
+	p.publish(event.Dropped, value)
 
 	return nil
 }
@@ -144,3 +161,174 @@ func (p *Stack[T]) Peek() (T, error) {
 func (p *Stack[T]) GetStack() []T {
 	return p.content
 }
+
+// PopN pops the last 'n' elements of the stack and returns them to the caller
+// in the order in which single calls to Pop would have returned them.
+// The operation is atomic: if the stack holds fewer than 'n' elements
+// an 'Underflow error' is returned and the stack is left untouched.
+// A negative 'n' returns a 'Negative count error'.
+func (p *Stack[T]) PopN(n int) ([]T, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n < 0 {
+		return nil, &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return nil, &UnderflowError{}
+	}
+
+	return p.popN(n), nil
+}
+
+// PopUpToN pops at most 'n' elements of the stack and returns them to the
+// caller together with the number of elements taken. Contrary to PopN
+// no error is returned if the stack holds fewer than 'n' elements.
+func (p *Stack[T]) PopUpToN(n int) ([]T, int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	values := p.popN(p.available(n))
+
+	return values, len(values)
+}
+
+// PopAll pops all elements of the stack and returns them to the caller
+// in the order in which single calls to Pop would have returned them.
+func (p *Stack[T]) PopAll() []T {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.popN(len(p.content))
+}
+
+// PeekN gets the last 'n' elements of the stack and returns them to the caller
+// in the order in which single calls to Pop would have returned them.
+// If the stack holds fewer than 'n' elements an 'Underflow error' is returned,
+// a negative 'n' returns a 'Negative count error'.
+func (p *Stack[T]) PeekN(n int) ([]T, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	if n < 0 {
+		return nil, &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return nil, &UnderflowError{}
+	}
+
+	return p.peekN(n), nil
+}
+
+// PeekUpToN gets at most 'n' elements of the stack and returns them to the
+// caller together with the number of elements returned.
+func (p *Stack[T]) PeekUpToN(n int) ([]T, int) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	values := p.peekN(p.available(n))
+
+	return values, len(values)
+}
+
+// DropN drops the last 'n' elements of the stack.
+// The operation is atomic: if the stack holds fewer than 'n' elements
+// an 'Underflow error' is returned and the stack is left untouched.
+// A negative 'n' returns a 'Negative count error'.
+func (p *Stack[T]) DropN(n int) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if n < 0 {
+		return &NegativeCountError{}
+	}
+
+	if n > len(p.content) {
+		return &UnderflowError{}
+	}
+
+	p.dropN(n, event.Dropped)
+
+	return nil
+}
+
+// DropUpToN drops at most 'n' elements of the stack and returns
+// the number of elements dropped.
+func (p *Stack[T]) DropUpToN(n int) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n = p.available(n)
+
+	p.dropN(n, event.Dropped)
+
+	return n
+}
+
+// available limits 'n' to the range of elements held by the stack.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Stack[T]) available(n int) int {
+	if n < 0 {
+		return 0
+	}
+
+	if n > len(p.content) {
+		return len(p.content)
+	}
+
+	return n
+}
+
+// peekN returns a copy of the last 'n' elements in reversed order.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Stack[T]) peekN(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+
+	values := make([]T, n)
+
+	for i := 0; i < n; i++ {
+		values[i] = p.content[len(p.content)-1-i]
+	}
+
+	return values
+}
+
+// popN removes the last 'n' elements and returns them in reversed order.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Stack[T]) popN(n int) []T {
+	values := p.peekN(n)
+
+	p.dropN(n, event.Popped)
+
+	return values
+}
+
+// dropN removes the last 'n' elements and reports them
+// to the subscribers using the provided event kind.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Stack[T]) dropN(n int, kind event.Kind) {
+	if n <= 0 {
+		return
+	}
+
+	if p.events.Active() {
+		p.publish(kind, p.peekN(n)...)
+	}
+
+	var zero T
+
+	for i := len(p.content) - n; i < len(p.content); i++ {
+		p.content[i] = zero
+	}
+
+	p.content = p.content[:len(p.content)-n]
+	p.notify()
+}
@@ -1,6 +1,7 @@
 package stack_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -211,3 +212,339 @@ func ExampleStack_overflow() {
 	// Output:
 	// ERROR: Overflow error
 }
+
+func ExampleStack_PopN() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3, 4, 5)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, err := myIntStack.PopN(3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PopN: %v\n", values)
+
+	_, err = myIntStack.PopN(3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntStack)
+	// Output:
+	// PopN: [5 4 3]
+	// ERROR: Underflow error
+	// Content: [1,2]
+}
+
+func ExampleStack_PopN_negative() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntStack.PopN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntStack.PeekN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntStack.DropN(-1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntStack)
+	// Output:
+	// ERROR: Negative count error
+	// ERROR: Negative count error
+	// ERROR: Negative count error
+	// Content: [1,2,3]
+}
+
+func ExampleStack_PopUpToN() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, n := myIntStack.PopUpToN(5)
+
+	fmt.Printf("PopUpToN: %v (%d)\n", values, n)
+	fmt.Printf("Length: %d\n", myIntStack.Length())
+	// Output:
+	// PopUpToN: [3 2 1] (3)
+	// Length: 0
+}
+
+func ExampleStack_PeekN() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	values, err := myIntStack.PeekN(2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PeekN: %v\n", values)
+	fmt.Printf("Content: %v\n", myIntStack)
+	// Output:
+	// PeekN: [3 2]
+	// Content: [1,2,3]
+}
+
+func ExampleStack_DropN() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntStack.DropN(2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntStack)
+	fmt.Printf("DropUpToN: %d\n", myIntStack.DropUpToN(2))
+	fmt.Printf("Content: %v\n", myIntStack)
+	// Output:
+	// Content: [1]
+	// DropUpToN: 1
+	// Content: []
+}
+
+func ExampleStack_PopAll() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("PopAll: %v\n", myIntStack.PopAll())
+	fmt.Printf("Length: %d\n", myIntStack.Length())
+	// Output:
+	// PopAll: [3 2 1]
+	// Length: 0
+}
+
+func ExampleStack_Chan() {
+	var err error
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	out := myIntStack.Chan(ctx)
+
+	for i := 1; i <= 3; i++ {
+		fmt.Printf("Received: %d\n", <-out)
+	}
+	// Output:
+	// Received: 3
+	// Received: 2
+	// Received: 1
+}
+
+func ExampleStack_Sink() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	myIntStack := stack.New[int](1)
+
+	sink := myIntStack.Sink(ctx)
+
+	go func() {
+		for i := 1; i <= 10; i++ {
+			sink <- i
+		}
+
+		close(sink)
+	}()
+
+	out := myIntStack.Chan(ctx)
+
+	sum := 0
+
+	for i := 1; i <= 10; i++ {
+		sum += <-out
+	}
+
+	fmt.Printf("Sum: %d\n", sum)
+	// Output:
+	// Sum: 55
+}
+
+func ExampleImmutable_Push() {
+	empty := stack.NewImmutable[string]()
+
+	hello := empty.Push("Hello")
+	world := hello.Push("World")
+	there := hello.Push("there")
+
+	fmt.Printf("Content: %v\n", empty)
+	fmt.Printf("Content: %v\n", hello)
+	fmt.Printf("Content: %v (%d)\n", world, world.Length())
+	fmt.Printf("Content: %v (%d)\n", there, there.Length())
+	// Output:
+	// Content: []
+	// Content: [Hello]
+	// Content: [Hello,World] (2)
+	// Content: [Hello,there] (2)
+}
+
+func ExampleImmutable_Pop() {
+	myIntStack := stack.NewImmutable[int]().Push(13, 42)
+
+	value, popped, err := myIntStack.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Pop: %d\n", value)
+	fmt.Printf("Content: %v\n", popped)
+	fmt.Printf("Content: %v\n", myIntStack)
+
+	_, err = stack.NewImmutable[int]().Drop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Pop: 42
+	// Content: [13]
+	// Content: [13,42]
+	// ERROR: Underflow error
+}
+
+func ExampleStack_Snapshot() {
+	var err error
+
+	myIntStack := stack.New[int](0)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	snapshot := myIntStack.Snapshot()
+
+	err = myIntStack.Drop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myIntStack)
+
+	for _, value := range snapshot.GetStack() {
+		fmt.Printf("%d\n", value)
+	}
+	// Output:
+	// Content: [1,2]
+	// 1
+	// 2
+	// 3
+}
+
+func ExampleMinMax_Min() {
+	var err error
+
+	myIntStack := stack.NewMinMax[int](0)
+
+	err = myIntStack.Push(5, 3, 8, 1)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for myIntStack.Length() > 0 {
+		minValue, _ := myIntStack.Min()
+		maxValue, _ := myIntStack.Max()
+
+		fmt.Printf("Content: %v, Min: %d, Max: %d\n", myIntStack, minValue, maxValue)
+
+		_ = myIntStack.Drop()
+	}
+
+	_, err = myIntStack.Min()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stdout, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Content: [5,3,8,1], Min: 1, Max: 8
+	// Content: [5,3,8], Min: 3, Max: 8
+	// Content: [5,3], Min: 3, Max: 5
+	// Content: [5], Min: 5, Max: 5
+	// ERROR: Underflow error
+}
+
+func ExampleStack_Subscribe() {
+	var err error
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	myIntStack := stack.New[int](0)
+
+	events := myIntStack.Subscribe(ctx)
+
+	err = myIntStack.Push(1, 2, 3)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_, err = myIntStack.Pop()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	err = myIntStack.DropN(2)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	cancel()
+
+	for e := range events {
+		fmt.Printf("%s: %d\n", e.Kind, e.Value)
+	}
+	// Output:
+	// Pushed: 1
+	// Pushed: 2
+	// Pushed: 3
+	// Popped: 3
+	// Dropped: 2
+	// Dropped: 1
+}
@@ -0,0 +1,468 @@
+/*
+Package tiered is a simple generic implementation of a two level cache.
+The first level (L1) is an in-memory LRU cache. Entries evicted from it are spilled to
+the second level (L2), a directory holding one file per entry, instead of being lost.
+An entry found in L2 is promoted back to L1. L2 has its own size limit, if it is exceeded
+the least recently used L2 entries are deleted. The index of L2 is persisted, so the
+entries written to disk survive a restart of the process.
+*/
+package tiered
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/piccobit/generics/codec"
+	"github.com/piccobit/generics/lrucache"
+)
+
+const (
+	indexName   = "index.json"
+	entrySuffix = ".entry"
+
+	// DefaultL2MaxBytes is the default size limit of L2 in bytes.
+	DefaultL2MaxBytes = 256 * 1024 * 1024
+)
+
+// TierStats holds the statistics of a single level of the cache.
+type TierStats struct {
+	Hits    uint
+	Misses  uint
+	Entries int
+	Bytes   int64
+}
+
+// Stats holds the statistics of a tiered cache.
+type Stats struct {
+	L1 TierStats
+	L2 TierStats
+	// Spills counts the entries moved from L1 to L2.
+	Spills uint
+	// Promotions counts the entries moved from L2 to L1.
+	Promotions uint
+	// Evictions counts the entries deleted from L2 to enforce its size limit.
+	Evictions uint
+	// Errors counts the entries lost because they couldn't be written or read
+	// or because they are larger than the size limit of L2.
+	Errors uint
+}
+
+type entry struct {
+	ID   string `json:"id"`
+	Size int64  `json:"-"`
+}
+
+type Tiered[T any] struct {
+	l1       *lrucache.LRUCache[T]
+	dir      string
+	codec    codec.Codec[T]
+	maxBytes int64
+	index    map[string]*list.Element
+	order    *list.List
+	bytes    int64
+	stats    Stats
+	closed   bool
+	mutex    sync.Mutex
+}
+
+type IDInterface interface {
+	ID() string
+}
+
+type ClosedError struct{}
+
+func (e *ClosedError) Error() string {
+	return "Closed error"
+}
+
+// Option configures optional behaviour of a tiered cache.
+type Option func(*options)
+
+type options struct {
+	maxBytes int64
+}
+
+// WithL2MaxBytes sets the size limit of L2 in bytes.
+// The default limit is 'DefaultL2MaxBytes'.
+func WithL2MaxBytes(maxBytes int64) Option {
+	return func(o *options) {
+		o.maxBytes = maxBytes
+	}
+}
+
+// Open opens or creates a tiered cache storing its L2 in the provided directory.
+// The 'maxSize' parameter allows to specify the maximum size of L1.
+// The 'valueCodec' parameter defines how values are stored on disk, if it is
+// nil the values are stored as JSON.
+// Entries of the directory which are not part of the persisted index,
+// e.g. because the process crashed, are deleted.
+func Open[T any](dir string, maxSize int, valueCodec codec.Codec[T], opts ...Option) (*Tiered[T], error) {
+	o := options{maxBytes: DefaultL2MaxBytes}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if valueCodec == nil {
+		valueCodec = codec.JSONCodec[T]{}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	cache := Tiered[T]{
+		l1:       lrucache.New[T](maxSize),
+		dir:      dir,
+		codec:    valueCodec,
+		maxBytes: o.maxBytes,
+		index:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+
+	if err := cache.load(); err != nil {
+		return nil, err
+	}
+
+	cache.l1.SetEvictionCallback(cache.spill)
+
+	return &cache, nil
+}
+
+// Get returns the value stored by the provided ID.
+// A value found in L1 becomes its most recently used one,
+// a value found in L2 is promoted to L1.
+// If the ID doesn't exist 'false' is returned.
+func (p *Tiered[T]) Get(id string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var dummy T
+
+	if p.closed {
+		return dummy, false
+	}
+
+	if value, ok := p.l1.Get(id); ok {
+		p.stats.L1.Hits++
+
+		// The LRU cache doesn't reorder its entries on read access,
+		// adding the entry again makes it the most recently used one.
+		_ = p.l1.AddByID(id, value)
+
+		return value, true
+	}
+
+	p.stats.L1.Misses++
+
+	element, ok := p.index[id]
+	if !ok {
+		p.stats.L2.Misses++
+
+		return dummy, false
+	}
+
+	data, err := os.ReadFile(p.path(id))
+
+	var value T
+
+	if err == nil {
+		value, err = p.codec.Decode(data)
+	}
+
+	p.remove(element)
+
+	if err != nil {
+		p.stats.L2.Misses++
+		p.stats.Errors++
+
+		return dummy, false
+	}
+
+	p.stats.L2.Hits++
+	p.stats.Promotions++
+
+	// Adding the value to L1 may spill another entry to L2.
+	_ = p.l1.AddByID(id, value)
+
+	return value, true
+}
+
+// Contains checks if one of the levels contains an element with
+// the provided ID.
+func (p *Tiered[T]) Contains(id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if _, ok := p.l1.Contains(id); ok {
+		return true
+	}
+
+	_, ok := p.index[id]
+
+	return ok
+}
+
+// AddByID adds the provided argument with the provided ID to L1.
+// If L1 has already reached its maximum size, its oldest entry is spilled to L2.
+// An older value with the same ID stored in L2 is deleted.
+func (p *Tiered[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return &ClosedError{}
+	}
+
+	if element, ok := p.index[id]; ok {
+		p.remove(element)
+	}
+
+	return p.l1.AddByID(id, arg)
+}
+
+// Add adds the provided argument to L1.
+// The ID used is either provided using the ID interface or generated internally.
+// If L1 has already reached its maximum size, its oldest entry is spilled to L2.
+// An older value with the same ID stored in L2 is deleted.
+func (p *Tiered[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Remove removes the entry with the provided ID from both levels.
+// If the ID doesn't exist 'false' is returned.
+func (p *Tiered[T]) Remove(id string) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	removed := p.l1.Remove(id)
+
+	if element, ok := p.index[id]; ok {
+		p.remove(element)
+
+		removed = true
+	}
+
+	return removed
+}
+
+// Stats returns the statistics of both levels.
+func (p *Tiered[T]) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := p.stats
+	stats.L1.Entries = len(p.l1.GetCache())
+	stats.L2.Entries = p.order.Len()
+	stats.L2.Bytes = p.bytes
+
+	return stats
+}
+
+// Sync persists the index of L2.
+func (p *Tiered[T]) Sync() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return &ClosedError{}
+	}
+
+	return p.save()
+}
+
+// Close spills all entries of L1 to L2, from the least to the most recently
+// used one, so the most recently used entries are kept if the size limit of
+// L2 doesn't allow to keep all, and persists the index of L2.
+// The cache can't be used afterwards, closing it again does nothing.
+func (p *Tiered[T]) Close() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return nil
+	}
+
+	for _, id := range p.l1.GetIDs() {
+		if value, ok := p.l1.Get(id); ok {
+			p.spill(id, value)
+		}
+	}
+
+	p.closed = true
+
+	return p.save()
+}
+
+// spill writes the provided entry to L2 and deletes the least recently used
+// L2 entries until the size limit is met again. An entry larger than the
+// size limit is dropped instead. It is called by L1 for every
+// evicted entry, which only happens while the mutex is held.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Tiered[T]) spill(id string, value T) {
+	if element, ok := p.index[id]; ok {
+		p.remove(element)
+	}
+
+	data, err := p.codec.Encode(value)
+	if err == nil && int64(len(data)) > p.maxBytes {
+		// The entry would evict all other entries and itself.
+		p.stats.Errors++
+
+		return
+	}
+
+	if err == nil {
+		err = os.WriteFile(p.path(id), data, 0o644)
+	}
+
+	if err != nil {
+		p.stats.Errors++
+
+		return
+	}
+
+	p.stats.Spills++
+	p.index[id] = p.order.PushFront(&entry{ID: id, Size: int64(len(data))})
+	p.bytes += int64(len(data))
+
+	for p.bytes > p.maxBytes && p.order.Len() > 0 {
+		p.remove(p.order.Back())
+		p.stats.Evictions++
+	}
+}
+
+// remove deletes the provided L2 entry.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Tiered[T]) remove(element *list.Element) {
+	l2Entry := p.order.Remove(element).(*entry)
+
+	delete(p.index, l2Entry.ID)
+	p.bytes -= l2Entry.Size
+
+	_ = os.Remove(p.path(l2Entry.ID))
+}
+
+// path returns the path of the file storing the entry with the provided ID.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Tiered[T]) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(p.dir, hex.EncodeToString(sum[:])+entrySuffix)
+}
+
+// save writes the index of L2, from the most to the least recently used entry.
+// The index is written to a temporary file first, so a crash never leaves
+// a partially written index behind.
+// This function is only used internally and does not use
+// the mutex to lock during the read access.
+func (p *Tiered[T]) save() error {
+	entries := make([]*entry, 0, p.order.Len())
+
+	for element := p.order.Front(); element != nil; element = element.Next() {
+		entries = append(entries, element.Value.(*entry))
+	}
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(p.dir, indexName+".*")
+	if err != nil {
+		return err
+	}
+
+	if _, err = tmp.Write(data); err == nil {
+		err = tmp.Sync()
+	}
+
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(p.dir, indexName))
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+
+	return err
+}
+
+// load reads the persisted index of L2, drops the entries whose files are
+// missing and deletes the files which are not part of the index.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Tiered[T]) load() error {
+	var entries []*entry
+
+	data, err := os.ReadFile(filepath.Join(p.dir, indexName))
+
+	switch {
+	case err == nil:
+		if err = json.Unmarshal(data, &entries); err != nil {
+			return err
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	known := make(map[string]struct{}, len(entries))
+
+	for _, l2Entry := range entries {
+		if _, ok := p.index[l2Entry.ID]; ok {
+			continue
+		}
+
+		info, err := os.Stat(p.path(l2Entry.ID))
+		if err != nil {
+			continue
+		}
+
+		l2Entry.Size = info.Size()
+		p.index[l2Entry.ID] = p.order.PushBack(l2Entry)
+		p.bytes += l2Entry.Size
+		known[filepath.Base(p.path(l2Entry.ID))] = struct{}{}
+	}
+
+	files, err := os.ReadDir(p.dir)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		name := file.Name()
+
+		if _, ok := known[name]; !ok && (strings.HasSuffix(name, entrySuffix) || strings.HasPrefix(name, indexName+".")) {
+			_ = os.Remove(filepath.Join(p.dir, name))
+		}
+	}
+
+	for p.bytes > p.maxBytes && p.order.Len() > 0 {
+		p.remove(p.order.Back())
+	}
+
+	return nil
+}
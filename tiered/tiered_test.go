@@ -0,0 +1,228 @@
+package tiered_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/piccobit/generics/tiered"
+)
+
+type car struct {
+	Name       string
+	Colour     string
+	Horsepower int
+}
+
+func ExampleTiered_Get() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myCarCache, err := tiered.Open[car](dir, 2, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for _, c := range []car{
+		{Name: "Ferrari", Colour: "red", Horsepower: 400},
+		{Name: "Porsche", Colour: "silver", Horsepower: 300},
+		{Name: "Trabant", Colour: "blue", Horsepower: 26},
+	} {
+		if err = myCarCache.AddByID(c.Name, c); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	stats := myCarCache.Stats()
+	fmt.Printf("L1: %d, L2: %d, Spills: %d\n", stats.L1.Entries, stats.L2.Entries, stats.Spills)
+
+	value, ok := myCarCache.Get("Ferrari")
+	fmt.Printf("Get: %v %t\n", value, ok)
+
+	stats = myCarCache.Stats()
+	fmt.Printf("L1 hits: %d, L2 hits: %d, Promotions: %d\n", stats.L1.Hits, stats.L2.Hits, stats.Promotions)
+	fmt.Printf("L1: %d, L2: %d\n", stats.L1.Entries, stats.L2.Entries)
+	// Output:
+	// L1: 2, L2: 1, Spills: 1
+	// Get: {Ferrari red 400} true
+	// L1 hits: 0, L2 hits: 1, Promotions: 1
+	// L1: 2, L2: 1
+}
+
+func ExampleTiered_Close() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringCache, err := tiered.Open[string](dir, 10, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	_ = myStringCache.AddByID("greeting", "Hello")
+	_ = myStringCache.AddByID("subject", "World")
+
+	if err = myStringCache.Close(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	myStringCache, err = tiered.Open[string](dir, 10, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringCache.Close()
+
+	fmt.Printf("L2: %d\n", myStringCache.Stats().L2.Entries)
+
+	value, ok := myStringCache.Get("greeting")
+	fmt.Printf("Get: %s %t\n", value, ok)
+	// Output:
+	// L2: 2
+	// Get: Hello true
+}
+
+func ExampleTiered_Close_limit() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	// L2 only holds two of the JSON encoded values.
+	myStringCache, err := tiered.Open[string](dir, 10, nil, tiered.WithL2MaxBytes(8))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		_ = myStringCache.AddByID(id, id+id)
+	}
+
+	_, _ = myStringCache.Get("a")
+
+	if err = myStringCache.Close(); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Close again: %v\n", myStringCache.Close())
+
+	myStringCache, err = tiered.Open[string](dir, 10, nil, tiered.WithL2MaxBytes(8))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringCache.Close()
+
+	// The most recently used entries survive.
+	for _, id := range []string{"a", "b", "c", "d"} {
+		value, ok := myStringCache.Get(id)
+		fmt.Printf("%s: %q %t\n", id, value, ok)
+	}
+	// Output:
+	// Close again: <nil>
+	// a: "aa" true
+	// b: "" false
+	// c: "" false
+	// d: "dd" true
+}
+
+func ExampleWithL2MaxBytes() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	// Every value takes 7 bytes as JSON, so L2 holds two of them.
+	myStringCache, err := tiered.Open[string](dir, 1, nil, tiered.WithL2MaxBytes(14))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringCache.Close()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		_ = myStringCache.AddByID(id, "value")
+	}
+
+	stats := myStringCache.Stats()
+	fmt.Printf("L2: %d entries, %d bytes, Evictions: %d\n", stats.L2.Entries, stats.L2.Bytes, stats.Evictions)
+	fmt.Printf("Contains a: %t, b: %t\n", myStringCache.Contains("a"), myStringCache.Contains("b"))
+	// Output:
+	// L2: 2 entries, 14 bytes, Evictions: 1
+	// Contains a: false, b: true
+}
+
+func ExampleWithL2MaxBytes_oversized() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringCache, err := tiered.Open[string](dir, 1, nil, tiered.WithL2MaxBytes(14))
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringCache.Close()
+
+	// L2 is full after spilling "a" & "b".
+	for _, id := range []string{"a", "b"} {
+		_ = myStringCache.AddByID(id, "value")
+	}
+
+	// The spilled value is larger than L2, so it is dropped
+	// instead of evicting all other entries.
+	_ = myStringCache.AddByID("big", "a value larger than L2")
+	_ = myStringCache.AddByID("d", "value")
+
+	stats := myStringCache.Stats()
+	fmt.Printf("L2: %d entries, Evictions: %d, Errors: %d\n", stats.L2.Entries, stats.Evictions, stats.Errors)
+	fmt.Printf("Contains a: %t, big: %t\n", myStringCache.Contains("a"), myStringCache.Contains("big"))
+	// Output:
+	// L2: 2 entries, Evictions: 0, Errors: 1
+	// Contains a: true, big: false
+}
+
+func ExampleTiered_Get_recency() {
+	dir, err := os.MkdirTemp("", "tiered")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer os.RemoveAll(dir)
+
+	myStringCache, err := tiered.Open[string](dir, 2, nil)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	defer myStringCache.Close()
+
+	_ = myStringCache.AddByID("hot", "value")
+	_ = myStringCache.AddByID("cold", "value")
+
+	// Reading the older entry keeps it in L1, the unused one is spilled.
+	_, _ = myStringCache.Get("hot")
+	_ = myStringCache.AddByID("new", "value")
+
+	stats := myStringCache.Stats()
+	fmt.Printf("L1 hits: %d, Spills: %d\n", stats.L1.Hits, stats.Spills)
+
+	_, _ = myStringCache.Get("hot")
+	fmt.Printf("L1 hits: %d, Promotions: %d\n", myStringCache.Stats().L1.Hits, myStringCache.Stats().Promotions)
+	// Output:
+	// L1 hits: 1, Spills: 1
+	// L1 hits: 2, Promotions: 0
+}
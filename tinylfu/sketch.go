@@ -0,0 +1,148 @@
+package tinylfu
+
+import (
+	"hash/fnv"
+)
+
+const sketchDepth = 4
+
+// sketch is a count-min sketch estimating the access frequencies of IDs.
+// A doorkeeper Bloom filter absorbs the first access of every ID, so IDs
+// seen only once do not pollute the counters. All counters are halved and
+// the doorkeeper is cleared after a fixed number of recorded accesses, so
+// that the sketch forgets old history.
+type sketch struct {
+	counters   [sketchDepth][]uint8
+	doorkeeper []uint64
+	mask       uint64
+	additions  int
+	sampleSize int
+}
+
+func newSketch(maxSize int) *sketch {
+	width := uint64(16)
+
+	for width < uint64(maxSize)*4 {
+		width <<= 1
+	}
+
+	s := &sketch{
+		doorkeeper: make([]uint64, width/64+1),
+		mask:       width - 1,
+		sampleSize: 10 * maxSize,
+	}
+
+	for i := range s.counters {
+		s.counters[i] = make([]uint8, width)
+	}
+
+	if s.sampleSize < 16 {
+		s.sampleSize = 16
+	}
+
+	return s
+}
+
+// increment records an access to the provided ID.
+func (p *sketch) increment(id string) {
+	h1, h2 := hashes(id)
+
+	if !p.admit(h1, h2) {
+		p.count()
+
+		return
+	}
+
+	for i := range p.counters {
+		idx := (h1 + uint64(i)*h2) & p.mask
+
+		if p.counters[i][idx] < 15 {
+			p.counters[i][idx]++
+		}
+	}
+
+	p.count()
+}
+
+// estimate returns the estimated access frequency of the provided ID.
+func (p *sketch) estimate(id string) int {
+	h1, h2 := hashes(id)
+
+	minimum := uint8(255)
+
+	for i := range p.counters {
+		idx := (h1 + uint64(i)*h2) & p.mask
+
+		if p.counters[i][idx] < minimum {
+			minimum = p.counters[i][idx]
+		}
+	}
+
+	estimate := int(minimum)
+
+	if p.contains(h1, h2) {
+		estimate++
+	}
+
+	return estimate
+}
+
+// admit adds the ID to the doorkeeper and reports whether it was already part of it.
+func (p *sketch) admit(h1, h2 uint64) bool {
+	present := true
+
+	for i := uint64(0); i < 2; i++ {
+		bit := (h1 + i*h2) & p.mask
+
+		if p.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			present = false
+			p.doorkeeper[bit/64] |= 1 << (bit % 64)
+		}
+	}
+
+	return present
+}
+
+// contains reports whether the ID is part of the doorkeeper.
+func (p *sketch) contains(h1, h2 uint64) bool {
+	for i := uint64(0); i < 2; i++ {
+		bit := (h1 + i*h2) & p.mask
+
+		if p.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// count counts the recorded accesses and resets the sketch if the sample size is reached.
+func (p *sketch) count() {
+	p.additions++
+
+	if p.additions < p.sampleSize {
+		return
+	}
+
+	for i := range p.counters {
+		for j := range p.counters[i] {
+			p.counters[i][j] >>= 1
+		}
+	}
+
+	for i := range p.doorkeeper {
+		p.doorkeeper[i] = 0
+	}
+
+	p.additions /= 2
+}
+
+func hashes(id string) (uint64, uint64) {
+	h := fnv.New64a()
+
+	_, _ = h.Write([]byte(id))
+
+	sum := h.Sum64()
+
+	return sum, (sum >> 32) | 1
+}
@@ -0,0 +1,323 @@
+/*
+Package tinylfu is a simple generic implementation of a W-TinyLFU cache.
+New entries are added to a small LRU window. Entries leaving the window are only
+admitted to the main area, a segmented LRU with a probationary and a protected
+segment, if their estimated access frequency is higher than the one of the entry
+they would replace. The frequencies are estimated using a count-min sketch with a
+doorkeeper Bloom filter, which also remembers entries that are no longer cached.
+*/
+package tinylfu
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type segment int
+
+const (
+	window segment = iota
+	probation
+	protected
+)
+
+type item[T any] struct {
+	id      string
+	value   T
+	segment segment
+}
+
+type TinyLFU[T any] struct {
+	window          *list.List
+	probation       *list.List
+	protected       *list.List
+	index           map[string]*list.Element
+	sketch          *sketch
+	maxSize         int
+	maxWindow       int
+	maxProtected    int
+	hitsCounter     uint
+	missedCounter   uint
+	admittedCounter uint
+	rejectedCounter uint
+	mutex           sync.RWMutex
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+type IDInterface interface {
+	ID() string
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// New returns the pointer to a new W-TinyLFU cache.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the cache. One percent of the
+// cache is used for the window, 80 percent of the
+// main area are used for the protected segment.
+func New[T any](maxSize int) *TinyLFU[T] {
+	maxWindow := maxSize / 100
+	if maxWindow < 1 {
+		maxWindow = 1
+	}
+
+	cache := TinyLFU[T]{
+		window:       list.New(),
+		probation:    list.New(),
+		protected:    list.New(),
+		index:        make(map[string]*list.Element),
+		sketch:       newSketch(maxSize),
+		maxSize:      maxSize,
+		maxWindow:    maxWindow,
+		maxProtected: (maxSize - maxWindow) * 80 / 100,
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided ID.
+// If the ID doesn't exist 'false' is returned.
+func (p *TinyLFU[T]) Get(id string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.sketch.increment(id)
+
+	element, ok := p.index[id]
+	if !ok {
+		var dummy T
+
+		p.missedCounter++
+
+		return dummy, false
+	}
+
+	p.hitsCounter++
+
+	p.touch(element)
+
+	return element.Value.(*item[T]).value, true
+}
+
+// Contains checks if the cache contains an element with
+// the provided ID.
+func (p *TinyLFU[T]) Contains(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	_, ok := p.index[id]
+
+	return ok
+}
+
+// String implements the Stringer interface.
+// The entries are listed by segment, window first,
+// each from the least to the most recently used.
+func (p *TinyLFU[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	followingItems := false
+
+	for _, l := range []*list.List{p.window, p.probation, p.protected} {
+		for element := l.Back(); element != nil; element = element.Prev() {
+			if followingItems {
+				str.WriteString(",")
+			} else {
+				followingItems = true
+			}
+
+			_, _ = fmt.Fprintf(&str, "%v", element.Value.(*item[T]).value)
+		}
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// AddByID adds the provided argument with the provided ID to the cache.
+// New items are added to the window. If the window is full, its least
+// recently used entry is moved to the main area if there is space left
+// or if it is accessed more frequently than the entry it would replace,
+// otherwise it is dropped.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *TinyLFU[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize <= 0 {
+		return &OverflowError{}
+	}
+
+	p.sketch.increment(id)
+
+	if element, ok := p.index[id]; ok {
+		element.Value.(*item[T]).value = arg
+
+		p.touch(element)
+
+		return nil
+	}
+
+	p.index[id] = p.window.PushFront(&item[T]{id: id, value: arg, segment: window})
+
+	if p.window.Len() > p.maxWindow {
+		p.admit(p.window.Back())
+	}
+
+	return nil
+}
+
+// Add adds the provided argument to the cache.
+// The ID used is either provided using the ID interface or generated internally.
+// New items are added to the window. If the window is full, its least
+// recently used entry is moved to the main area if there is space left
+// or if it is accessed more frequently than the entry it would replace,
+// otherwise it is dropped.
+// If the added item is already part of the cache its value is updated
+// and it is treated as accessed.
+func (p *TinyLFU[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Stats returns the statistics of the provided cache,
+// the hits & the misses.
+func (p *TinyLFU[T]) Stats() (uint, uint) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.hitsCounter, p.missedCounter
+}
+
+// AdmissionStats returns the number of entries leaving the window
+// which were admitted to the main area and the number of entries
+// which were rejected by the admission policy.
+func (p *TinyLFU[T]) AdmissionStats() (uint, uint) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.admittedCounter, p.rejectedCounter
+}
+
+// Length returns the number of cached entries.
+func (p *TinyLFU[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return len(p.index)
+}
+
+// GetCache returns the cache content so that it can be
+// used in a 'for range' loop.
+func (p *TinyLFU[T]) GetCache() map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for id, element := range p.index {
+		content[id] = element.Value.(*item[T]).value
+	}
+
+	return content
+}
+
+// touch moves an accessed entry to the front of its segment and
+// promotes entries of the probationary segment to the protected one.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *TinyLFU[T]) touch(element *list.Element) {
+	cacheItem := element.Value.(*item[T])
+
+	switch cacheItem.segment {
+	case window:
+		p.window.MoveToFront(element)
+	case protected:
+		p.protected.MoveToFront(element)
+	case probation:
+		p.probation.Remove(element)
+
+		cacheItem.segment = protected
+		p.index[cacheItem.id] = p.protected.PushFront(cacheItem)
+
+		if p.protected.Len() > p.maxProtected {
+			demoted := p.protected.Back()
+			demotedItem := p.protected.Remove(demoted).(*item[T])
+
+			demotedItem.segment = probation
+			p.index[demotedItem.id] = p.probation.PushFront(demotedItem)
+		}
+	}
+}
+
+// admit moves the provided window entry to the probationary segment
+// if the admission policy accepts it, otherwise it is dropped.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *TinyLFU[T]) admit(element *list.Element) {
+	candidate := p.window.Remove(element).(*item[T])
+
+	if p.probation.Len()+p.protected.Len() >= p.maxSize-p.maxWindow {
+		victim := p.probation.Back()
+		if victim == nil {
+			victim = p.protected.Back()
+		}
+
+		if victim == nil {
+			p.rejectedCounter++
+
+			delete(p.index, candidate.id)
+
+			return
+		}
+
+		victimItem := victim.Value.(*item[T])
+
+		if p.sketch.estimate(candidate.id) <= p.sketch.estimate(victimItem.id) {
+			p.rejectedCounter++
+
+			delete(p.index, candidate.id)
+
+			return
+		}
+
+		if victimItem.segment == probation {
+			p.probation.Remove(victim)
+		} else {
+			p.protected.Remove(victim)
+		}
+
+		delete(p.index, victimItem.id)
+	}
+
+	p.admittedCounter++
+
+	candidate.segment = probation
+	p.index[candidate.id] = p.probation.PushFront(candidate)
+}
@@ -0,0 +1,147 @@
+package tinylfu_test
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/piccobit/generics/arccache"
+	"github.com/piccobit/generics/lfucache"
+	"github.com/piccobit/generics/lrucache"
+	"github.com/piccobit/generics/tinylfu"
+)
+
+type car struct {
+	name       string
+	colour     string
+	horsepower int
+}
+
+func (p *car) ID() string {
+	return p.name
+}
+
+func ExampleTinyLFU_AddByID() {
+	var err error
+
+	myStringCache := tinylfu.New[string](3)
+
+	for _, id := range []string{"a", "b", "c"} {
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _ = myStringCache.Get("a")
+		_, _ = myStringCache.Get("b")
+	}
+
+	// One-hit wonders don't push out the frequently used entries.
+	for i := 1; i <= 5; i++ {
+		id := "x" + strconv.Itoa(i)
+
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+
+	admitted, rejected := myStringCache.AdmissionStats()
+	fmt.Printf("Admitted: %d, Rejected: %d\n", admitted, rejected)
+	// Output:
+	// Content: [x5,a,b]
+	// Admitted: 2, Rejected: 5
+}
+
+func ExampleTinyLFU_Get_car() {
+	var err error
+
+	myCarCache := tinylfu.New[*car](10)
+
+	_, err = myCarCache.Add(&car{
+		name:       "VW",
+		colour:     "blue",
+		horsepower: 60,
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myCarCache.Get("VW")
+	fmt.Printf("%v: %v\n", *value, ok)
+
+	_, ok = myCarCache.Get("Corvette")
+	fmt.Printf("Corvette: %v\n", ok)
+
+	hits, misses := myCarCache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d\n", hits, misses)
+	// Output:
+	// {VW blue 60}: true
+	// Corvette: false
+	// Hits: 1, Misses: 1
+}
+
+type benchCache interface {
+	Get(id string) (string, bool)
+	AddByID(id string, arg string) error
+}
+
+// benchmarkHitRatio replays a synthetic Zipf distributed trace against the
+// cache and reports the resulting hit ratio as custom benchmark metric.
+func benchmarkHitRatio(b *testing.B, newCache func(maxSize int) benchCache) {
+	const (
+		maxSize = 500
+		keys    = 50000
+	)
+
+	zipf := rand.NewZipf(rand.New(rand.NewSource(42)), 1.1, 1, keys-1)
+	trace := make([]string, 100000)
+
+	for i := range trace {
+		trace[i] = strconv.FormatUint(zipf.Uint64(), 10)
+	}
+
+	hits := 0
+	requests := 0
+
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		cache := newCache(maxSize)
+
+		for _, id := range trace {
+			requests++
+
+			if _, ok := cache.Get(id); ok {
+				hits++
+
+				continue
+			}
+
+			_ = cache.AddByID(id, id)
+		}
+	}
+
+	b.ReportMetric(float64(hits)/float64(requests), "hit-ratio")
+}
+
+func BenchmarkHitRatio_Zipf(b *testing.B) {
+	b.Run("tinylfu", func(b *testing.B) {
+		benchmarkHitRatio(b, func(maxSize int) benchCache { return tinylfu.New[string](maxSize) })
+	})
+	b.Run("lrucache", func(b *testing.B) {
+		benchmarkHitRatio(b, func(maxSize int) benchCache { return lrucache.New[string](maxSize) })
+	})
+	b.Run("lfucache", func(b *testing.B) {
+		benchmarkHitRatio(b, func(maxSize int) benchCache { return lfucache.New[string](maxSize) })
+	})
+	b.Run("arccache", func(b *testing.B) {
+		benchmarkHitRatio(b, func(maxSize int) benchCache { return arccache.New[string](maxSize) })
+	})
+}
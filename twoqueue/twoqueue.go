@@ -0,0 +1,336 @@
+/*
+Package twoqueue is a simple generic implementation of a 2Q cache.
+New entries are added to a FIFO queue (A1in). Entries leaving this queue are only
+remembered by their ID in a ghost queue (A1out). Entries which are added again while
+they are remembered are promoted to the main LRU list (Am), so a single sequential
+scan only passes through A1in and does not wipe out the frequently used entries.
+*/
+package twoqueue
+
+import (
+	"container/list"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+type queue int
+
+const (
+	inQueue queue = iota
+	outQueue
+	mainQueue
+)
+
+type item[T any] struct {
+	id    string
+	value T
+	queue queue
+}
+
+type TwoQueue[T any] struct {
+	in            *list.List
+	out           *list.List
+	main          *list.List
+	index         map[string]*list.Element
+	maxSize       int
+	maxIn         int
+	maxOut        int
+	hitsCounter   uint
+	missedCounter uint
+	stats         SegmentStats
+	mutex         sync.RWMutex
+}
+
+// SegmentStats holds the number of entries moved between the
+// queues of the cache and the number of evicted entries.
+type SegmentStats struct {
+	Promotions uint
+	Evictions  uint
+}
+
+type UnderflowError struct{}
+type OverflowError struct{}
+
+type IDInterface interface {
+	ID() string
+}
+
+func (e *UnderflowError) Error() string {
+	return "Underflow error"
+}
+
+func (e *OverflowError) Error() string {
+	return "Overflow error"
+}
+
+// Option configures optional behaviour of a 2Q cache.
+type Option func(*options)
+
+type options struct {
+	inRatio  float64
+	outRatio float64
+}
+
+// WithInRatio sets the share of the cache used for the A1in queue.
+// The default ratio is 0.25.
+func WithInRatio(ratio float64) Option {
+	return func(o *options) {
+		o.inRatio = ratio
+	}
+}
+
+// WithOutRatio sets the number of IDs remembered in the A1out ghost queue,
+// relative to the maximum size of the cache. The default ratio is 0.5.
+func WithOutRatio(ratio float64) Option {
+	return func(o *options) {
+		o.outRatio = ratio
+	}
+}
+
+// New returns the pointer to a new 2Q cache.
+// The 'maxSize' parameter allows to specify a
+// maximum size for the cache.
+// The optional 'opts' parameters allow to change
+// the sizes of the queues.
+func New[T any](maxSize int, opts ...Option) *TwoQueue[T] {
+	o := options{
+		inRatio:  0.25,
+		outRatio: 0.5,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	maxIn := int(float64(maxSize) * o.inRatio)
+	if maxIn < 1 {
+		maxIn = 1
+	}
+
+	maxOut := int(float64(maxSize) * o.outRatio)
+	if maxOut < 1 {
+		maxOut = 1
+	}
+
+	cache := TwoQueue[T]{
+		in:      list.New(),
+		out:     list.New(),
+		main:    list.New(),
+		index:   make(map[string]*list.Element),
+		maxSize: maxSize,
+		maxIn:   maxIn,
+		maxOut:  maxOut,
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided ID.
+// If the ID doesn't exist 'false' is returned.
+func (p *TwoQueue[T]) Get(id string) (T, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	element, ok := p.index[id]
+	if !ok || element.Value.(*item[T]).queue == outQueue {
+		var dummy T
+
+		p.missedCounter++
+
+		return dummy, false
+	}
+
+	p.hitsCounter++
+
+	cacheItem := element.Value.(*item[T])
+
+	if cacheItem.queue == mainQueue {
+		p.main.MoveToFront(element)
+	}
+
+	return cacheItem.value, true
+}
+
+// Contains checks if the cache contains an element with
+// the provided ID.
+func (p *TwoQueue[T]) Contains(id string) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	element, ok := p.index[id]
+
+	return ok && element.Value.(*item[T]).queue != outQueue
+}
+
+// String implements the Stringer interface.
+// The entries of A1in are listed before the entries of Am,
+// each from the oldest to the most recently used.
+func (p *TwoQueue[T]) String() string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	var str strings.Builder
+
+	str.WriteString("[")
+
+	followingItems := false
+
+	for _, l := range []*list.List{p.in, p.main} {
+		for element := l.Back(); element != nil; element = element.Prev() {
+			if followingItems {
+				str.WriteString(",")
+			} else {
+				followingItems = true
+			}
+
+			_, _ = fmt.Fprintf(&str, "%v", element.Value.(*item[T]).value)
+		}
+	}
+
+	str.WriteString("]")
+
+	return str.String()
+}
+
+// AddByID adds the provided argument with the provided ID to the 2Q cache.
+// New items are added to the A1in queue, items which were evicted from
+// A1in recently are promoted to the Am list. If the cache has already
+// reached its maximum size, the oldest entry of A1in or the least
+// recently used entry of Am is dropped to make place for the new one.
+// If the added item is already part of the cache its value is updated.
+func (p *TwoQueue[T]) AddByID(id string, arg T) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.maxSize <= 0 {
+		return &OverflowError{}
+	}
+
+	if element, ok := p.index[id]; ok {
+		cacheItem := element.Value.(*item[T])
+
+		switch cacheItem.queue {
+		case mainQueue:
+			cacheItem.value = arg
+			p.main.MoveToFront(element)
+		case inQueue:
+			cacheItem.value = arg
+		case outQueue:
+			p.out.Remove(element)
+			p.reclaim()
+
+			cacheItem.value = arg
+			cacheItem.queue = mainQueue
+			p.index[id] = p.main.PushFront(cacheItem)
+			p.stats.Promotions++
+		}
+
+		return nil
+	}
+
+	p.reclaim()
+
+	p.index[id] = p.in.PushFront(&item[T]{id: id, value: arg, queue: inQueue})
+
+	return nil
+}
+
+// Add adds the provided argument to the 2Q cache.
+// The ID used is either provided using the ID interface or generated internally.
+// New items are added to the A1in queue, items which were evicted from
+// A1in recently are promoted to the Am list. If the cache has already
+// reached its maximum size, the oldest entry of A1in or the least
+// recently used entry of Am is dropped to make place for the new one.
+// If the added item is already part of the cache its value is updated.
+func (p *TwoQueue[T]) Add(arg T) (string, error) {
+	var id string
+
+	if idInterface, ok := any(arg).(IDInterface); ok {
+		id = idInterface.ID()
+	} else {
+		id = uuid.New().String()
+	}
+
+	return id, p.AddByID(id, arg)
+}
+
+// Stats returns the statistics of the provided cache,
+// the hits & the misses.
+func (p *TwoQueue[T]) Stats() (uint, uint) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.hitsCounter, p.missedCounter
+}
+
+// SegmentStats returns the number of promotions from A1out to Am
+// and the number of evicted entries.
+func (p *TwoQueue[T]) SegmentStats() SegmentStats {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.stats
+}
+
+// Length returns the number of cached entries.
+func (p *TwoQueue[T]) Length() int {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.in.Len() + p.main.Len()
+}
+
+// GetCache returns the cache content so that it can be
+// used in a 'for range' loop.
+func (p *TwoQueue[T]) GetCache() map[string]T {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	content := make(map[string]T)
+
+	for _, l := range []*list.List{p.in, p.main} {
+		for element := l.Front(); element != nil; element = element.Next() {
+			cacheItem := element.Value.(*item[T])
+			content[cacheItem.id] = cacheItem.value
+		}
+	}
+
+	return content
+}
+
+// reclaim makes place for a new entry if the cache is full.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *TwoQueue[T]) reclaim() {
+	if p.in.Len()+p.main.Len() < p.maxSize {
+		return
+	}
+
+	p.stats.Evictions++
+
+	if p.in.Len() > 0 && (p.in.Len() >= p.maxIn || p.main.Len() == 0) {
+		element := p.in.Back()
+		cacheItem := p.in.Remove(element).(*item[T])
+
+		var dummy T
+
+		cacheItem.value = dummy
+		cacheItem.queue = outQueue
+		p.index[cacheItem.id] = p.out.PushFront(cacheItem)
+
+		if p.out.Len() > p.maxOut {
+			ghost := p.out.Remove(p.out.Back()).(*item[T])
+
+			delete(p.index, ghost.id)
+		}
+
+		return
+	}
+
+	cacheItem := p.main.Remove(p.main.Back()).(*item[T])
+
+	delete(p.index, cacheItem.id)
+}
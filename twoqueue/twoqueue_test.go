@@ -0,0 +1,74 @@
+package twoqueue_test
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/piccobit/generics/twoqueue"
+)
+
+func ExampleTwoQueue_AddByID() {
+	var err error
+
+	myStringCache := twoqueue.New[string](4, twoqueue.WithInRatio(0.5), twoqueue.WithOutRatio(1))
+
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+
+	// Adding a recently evicted entry again promotes it to Am.
+	err = myStringCache.AddByID("a", "a")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+
+	// A sequential scan only passes through A1in.
+	for i := 1; i <= 10; i++ {
+		id := "x" + strconv.Itoa(i)
+
+		err = myStringCache.AddByID(id, id)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+	}
+
+	fmt.Printf("Content: %v\n", myStringCache)
+	fmt.Printf("Stats: %+v\n", myStringCache.SegmentStats())
+	// Output:
+	// Content: [b,c,d,e]
+	// Content: [c,d,e,a]
+	// Content: [x8,x9,x10,a]
+	// Stats: {Promotions:1 Evictions:12}
+}
+
+func ExampleTwoQueue_Get() {
+	var err error
+
+	myIntCache := twoqueue.New[int](10)
+
+	err = myIntCache.AddByID("foo", 13)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, ok := myIntCache.Get("foo")
+	fmt.Printf("foo: %d %v\n", value, ok)
+
+	_, ok = myIntCache.Get("bar")
+	fmt.Printf("bar: %v\n", ok)
+
+	hits, misses := myIntCache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d\n", hits, misses)
+	// Output:
+	// foo: 13 true
+	// bar: false
+	// Hits: 1, Misses: 1
+}
@@ -0,0 +1,139 @@
+package writecache
+
+import (
+	"context"
+	"sync"
+)
+
+// Store is the backing key-value store of a write cache.
+// Load returns 'false' if the key doesn't exist.
+type Store[K comparable, V any] interface {
+	Load(ctx context.Context, key K) (V, bool, error)
+	Store(ctx context.Context, key K, value V) error
+	Delete(ctx context.Context, key K) error
+}
+
+// BatchStore is implemented by stores which can write several entries at once.
+// A write-behind cache uses it to flush all dirty entries with a single call.
+type BatchStore[K comparable, V any] interface {
+	Store[K, V]
+	StoreBatch(ctx context.Context, entries map[K]V) error
+}
+
+// MemoryStoreStats holds the number of calls of a memory store.
+type MemoryStoreStats struct {
+	Loads   uint
+	Stores  uint
+	Deletes uint
+	Batches uint
+}
+
+// MemoryStore is a store keeping its entries in memory, e.g. to test a write cache.
+type MemoryStore[K comparable, V any] struct {
+	content map[K]V
+	err     error
+	stats   MemoryStoreStats
+	mutex   sync.RWMutex
+}
+
+// NewMemoryStore returns the pointer to a new, empty memory store.
+func NewMemoryStore[K comparable, V any]() *MemoryStore[K, V] {
+	store := MemoryStore[K, V]{content: make(map[K]V)}
+
+	return &store
+}
+
+// Load returns the value stored by the provided key.
+func (p *MemoryStore[K, V]) Load(ctx context.Context, key K) (V, bool, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var dummy V
+
+	if p.err != nil {
+		return dummy, false, p.err
+	}
+
+	p.stats.Loads++
+
+	value, ok := p.content[key]
+
+	return value, ok, nil
+}
+
+// Store stores the provided value by the provided key.
+func (p *MemoryStore[K, V]) Store(ctx context.Context, key K, value V) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.stats.Stores++
+	p.content[key] = value
+
+	return nil
+}
+
+// StoreBatch stores all provided entries at once.
+func (p *MemoryStore[K, V]) StoreBatch(ctx context.Context, entries map[K]V) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.stats.Batches++
+
+	for key, value := range entries {
+		p.stats.Stores++
+		p.content[key] = value
+	}
+
+	return nil
+}
+
+// Delete deletes the value stored by the provided key.
+func (p *MemoryStore[K, V]) Delete(ctx context.Context, key K) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+
+	p.stats.Deletes++
+	delete(p.content, key)
+
+	return nil
+}
+
+// SetError makes all following calls fail with the provided error,
+// until it is reset using nil.
+func (p *MemoryStore[K, V]) SetError(err error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.err = err
+}
+
+// Contains checks if the store contains the provided key, without
+// counting as call.
+func (p *MemoryStore[K, V]) Contains(key K) bool {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	_, ok := p.content[key]
+
+	return ok
+}
+
+// Stats returns the number of calls of the store.
+func (p *MemoryStore[K, V]) Stats() MemoryStoreStats {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	return p.stats
+}
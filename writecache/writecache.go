@@ -0,0 +1,630 @@
+/*
+Package writecache is a simple generic implementation of an LRU cache in front of a
+key-value store. Missing values are loaded from the store. Writes are either persisted
+synchronously (write-through) or buffered as dirty entries and flushed in batches by a
+background goroutine (write-behind), repeated writes of the same key are coalesced into
+a single one. Dirty entries are never evicted, if the cache is full of dirty entries
+they are flushed before a new entry is added.
+*/
+package writecache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFlushInterval is the default interval of the write-behind flushes.
+	DefaultFlushInterval = time.Second
+
+	// DefaultBatchSize is the default number of dirty entries
+	// triggering a write-behind flush.
+	DefaultBatchSize = 100
+)
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	version uint64
+	dirty   bool
+}
+
+// load tracks the loads of a key running concurrently, 'changed' is set
+// if the key is written or removed while they are running.
+type load struct {
+	count   int
+	changed bool
+}
+
+// Stats holds the statistics of a write cache.
+type Stats struct {
+	Hits   uint
+	Misses uint
+	// Loads counts the values loaded from the store.
+	Loads uint
+	// Writes counts the calls of 'AddByID'.
+	Writes uint
+	// Coalesced counts the writes & removals replacing a dirty entry
+	// before it was flushed.
+	Coalesced uint
+	// Flushes & FlushErrors count the write-behind flushes.
+	Flushes     uint
+	FlushErrors uint
+	// Evictions counts the clean entries evicted to make place for new ones.
+	Evictions uint
+	// Dirty is the number of entries & removals not flushed yet.
+	Dirty int
+}
+
+type Cache[K comparable, V any] struct {
+	store       Store[K, V]
+	lru         *list.List
+	index       map[K]*list.Element
+	deletes     map[K]uint64
+	loads       map[K]*load
+	maxSize     int
+	writeBehind bool
+	batchSize   int
+	version     uint64
+	dirty       int
+	stats       Stats
+	closed      bool
+	kick        chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+	cancel      context.CancelFunc
+	storeMutex  sync.Mutex
+	mutex       sync.Mutex
+}
+
+type ClosedError struct{}
+
+func (e *ClosedError) Error() string {
+	return "Closed error"
+}
+
+// Option configures optional behaviour of a write cache.
+type Option func(*options)
+
+type options struct {
+	writeBehind bool
+	interval    time.Duration
+	batchSize   int
+}
+
+// WithWriteBehind buffers the writes instead of persisting them synchronously.
+// The dirty entries are flushed every 'interval' or as soon as there are
+// 'batchSize' of them, whichever comes first. The store is written in batches
+// of at most 'batchSize' entries. Values below 1 select 'DefaultFlushInterval'
+// & 'DefaultBatchSize'.
+func WithWriteBehind(interval time.Duration, batchSize int) Option {
+	return func(o *options) {
+		o.writeBehind = true
+		o.interval = interval
+		o.batchSize = batchSize
+	}
+}
+
+// New returns the pointer to a new write cache in front of the provided store.
+// The 'maxSize' parameter allows to specify the maximum number of entries.
+// The optional 'opts' parameters allow to enable the write-behind mode,
+// by default the cache writes through.
+// A write-behind cache must be closed to stop its background goroutine
+// and to flush the remaining dirty entries.
+func New[K comparable, V any](maxSize int, store Store[K, V], opts ...Option) *Cache[K, V] {
+	var o options
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if maxSize < 1 {
+		maxSize = 1
+	}
+
+	if o.interval <= 0 {
+		o.interval = DefaultFlushInterval
+	}
+
+	if o.batchSize < 1 {
+		o.batchSize = DefaultBatchSize
+	}
+
+	cache := Cache[K, V]{
+		store:       store,
+		lru:         list.New(),
+		index:       make(map[K]*list.Element),
+		deletes:     make(map[K]uint64),
+		loads:       make(map[K]*load),
+		maxSize:     maxSize,
+		writeBehind: o.writeBehind,
+		batchSize:   o.batchSize,
+	}
+
+	if cache.writeBehind {
+		cache.kick = make(chan struct{}, 1)
+		cache.stop = make(chan struct{})
+		cache.done = make(chan struct{})
+
+		var ctx context.Context
+
+		ctx, cache.cancel = context.WithCancel(context.Background())
+
+		go cache.run(ctx, o.interval)
+	}
+
+	return &cache
+}
+
+// Get returns the value stored by the provided key, loading it from the store
+// if it isn't cached. If the key doesn't exist 'false' is returned.
+func (p *Cache[K, V]) Get(ctx context.Context, key K) (V, bool, error) {
+	p.mutex.Lock()
+
+	if element, ok := p.index[key]; ok {
+		p.lru.MoveToFront(element)
+		p.stats.Hits++
+		value := element.Value.(*entry[K, V]).value
+		p.mutex.Unlock()
+
+		return value, true, nil
+	}
+
+	p.stats.Misses++
+
+	var dummy V
+
+	// The store still holds the value of a removal not flushed yet.
+	if _, ok := p.deletes[key]; ok {
+		p.mutex.Unlock()
+
+		return dummy, false, nil
+	}
+
+	l, ok := p.loads[key]
+	if !ok {
+		l = &load{}
+		p.loads[key] = l
+	}
+
+	l.count++
+
+	p.mutex.Unlock()
+
+	value, ok, err := p.store.Load(ctx, key)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	l.count--
+
+	if l.count == 0 {
+		delete(p.loads, key)
+	}
+
+	if err != nil || !ok {
+		return dummy, false, err
+	}
+
+	p.stats.Loads++
+
+	// The loaded value may be outdated by a concurrent write or removal
+	// of the same key, it is returned but not cached in this case.
+	if !l.changed {
+		p.put(key, value, false)
+	}
+
+	return value, true, nil
+}
+
+// Contains checks if the cache contains the provided key,
+// without loading it from the store.
+func (p *Cache[K, V]) Contains(key K) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	_, ok := p.index[key]
+
+	return ok
+}
+
+// AddByID adds the provided value with the provided key to the cache.
+// In write-through mode the value is stored before it is cached, if storing
+// fails the error is returned and the cache is not changed.
+// In write-behind mode the value is stored by the next flush. If the cache
+// is full of dirty entries, they are flushed synchronously first.
+func (p *Cache[K, V]) AddByID(ctx context.Context, key K, value V) error {
+	if !p.writeBehind {
+		return p.writeThrough(ctx, key, value)
+	}
+
+	for {
+		p.mutex.Lock()
+
+		if p.closed {
+			p.mutex.Unlock()
+
+			return &ClosedError{}
+		}
+
+		if p.put(key, value, true) {
+			p.stats.Writes++
+			p.changed(key)
+			full := p.dirty >= p.batchSize
+			p.mutex.Unlock()
+
+			if full {
+				p.signal()
+			}
+
+			return nil
+		}
+
+		p.mutex.Unlock()
+
+		if err := p.Flush(ctx); err != nil {
+			return err
+		}
+	}
+}
+
+// Remove removes the value with the provided key from the cache & the store.
+// In write-behind mode the value is deleted from the store by the next flush.
+func (p *Cache[K, V]) Remove(ctx context.Context, key K) error {
+	if !p.writeBehind {
+		p.storeMutex.Lock()
+		defer p.storeMutex.Unlock()
+
+		if err := p.check(); err != nil {
+			return err
+		}
+
+		if err := p.store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+
+	p.mutex.Lock()
+
+	if p.closed {
+		p.mutex.Unlock()
+
+		return &ClosedError{}
+	}
+
+	p.version++
+	p.changed(key)
+
+	if element, ok := p.index[key]; ok {
+		if element.Value.(*entry[K, V]).dirty {
+			p.dirty--
+			p.stats.Coalesced++
+		}
+
+		p.lru.Remove(element)
+		delete(p.index, key)
+	}
+
+	full := false
+
+	if p.writeBehind {
+		if _, ok := p.deletes[key]; !ok {
+			p.dirty++
+		}
+
+		p.deletes[key] = p.version
+		full = p.dirty >= p.batchSize
+	}
+
+	p.mutex.Unlock()
+
+	if full {
+		p.signal()
+	}
+
+	return nil
+}
+
+// Flush writes all dirty entries & removals to the store. Entries changed
+// while the flush is running stay dirty. If the store fails, the entries
+// not written stay dirty and are retried by the next flush.
+func (p *Cache[K, V]) Flush(ctx context.Context) error {
+	p.storeMutex.Lock()
+	defer p.storeMutex.Unlock()
+
+	p.mutex.Lock()
+
+	writes := make(map[K]V)
+	versions := make(map[K]uint64)
+
+	for key, element := range p.index {
+		if e := element.Value.(*entry[K, V]); e.dirty {
+			writes[key] = e.value
+			versions[key] = e.version
+		}
+	}
+
+	deletes := make(map[K]uint64, len(p.deletes))
+
+	for key, version := range p.deletes {
+		deletes[key] = version
+	}
+
+	p.mutex.Unlock()
+
+	if len(writes) == 0 && len(deletes) == 0 {
+		return nil
+	}
+
+	written, err := p.write(ctx, writes)
+
+	var deleted []K
+
+	if err == nil {
+		for key := range deletes {
+			if err = p.store.Delete(ctx, key); err != nil {
+				break
+			}
+
+			deleted = append(deleted, key)
+		}
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stats.Flushes++
+
+	for _, key := range written {
+		if element, ok := p.index[key]; ok {
+			if e := element.Value.(*entry[K, V]); e.dirty && e.version == versions[key] {
+				e.dirty = false
+				p.dirty--
+			}
+		}
+	}
+
+	for _, key := range deleted {
+		if version, ok := p.deletes[key]; ok && version == deletes[key] {
+			delete(p.deletes, key)
+			p.dirty--
+		}
+	}
+
+	if err != nil {
+		p.stats.FlushErrors++
+	}
+
+	return err
+}
+
+// Close stops the background flushes and flushes the remaining dirty entries.
+// The cache can't be written afterwards. If the context is done before the
+// running background flush finished, the background flush is cancelled and
+// the error of the context is returned, the remaining entries aren't flushed.
+func (p *Cache[K, V]) Close(ctx context.Context) error {
+	p.mutex.Lock()
+
+	if p.closed {
+		p.mutex.Unlock()
+
+		return &ClosedError{}
+	}
+
+	p.closed = true
+
+	p.mutex.Unlock()
+
+	if p.writeBehind {
+		close(p.stop)
+
+		select {
+		case <-p.done:
+		case <-ctx.Done():
+			p.cancel()
+
+			return ctx.Err()
+		}
+
+		p.cancel()
+	}
+
+	return p.Flush(ctx)
+}
+
+// Stats returns the statistics of the cache.
+func (p *Cache[K, V]) Stats() Stats {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	stats := p.stats
+	stats.Dirty = p.dirty
+
+	return stats
+}
+
+// Length returns the number of cached entries.
+func (p *Cache[K, V]) Length() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.lru.Len()
+}
+
+// writeThrough stores the provided value and caches it afterwards.
+func (p *Cache[K, V]) writeThrough(ctx context.Context, key K, value V) error {
+	p.storeMutex.Lock()
+	defer p.storeMutex.Unlock()
+
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	if err := p.store.Store(ctx, key, value); err != nil {
+		return err
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.stats.Writes++
+	p.changed(key)
+
+	// Without dirty entries there is always a clean entry to evict.
+	p.put(key, value, false)
+
+	return nil
+}
+
+// write stores the provided entries, in batches if the store supports it,
+// and returns the keys written successfully.
+func (p *Cache[K, V]) write(ctx context.Context, writes map[K]V) ([]K, error) {
+	written := make([]K, 0, len(writes))
+
+	batchStore, ok := p.store.(BatchStore[K, V])
+	if !ok {
+		for key, value := range writes {
+			if err := p.store.Store(ctx, key, value); err != nil {
+				return written, err
+			}
+
+			written = append(written, key)
+		}
+
+		return written, nil
+	}
+
+	batch := make(map[K]V, p.batchSize)
+
+	for key, value := range writes {
+		batch[key] = value
+
+		if len(batch) < p.batchSize && len(batch)+len(written) < len(writes) {
+			continue
+		}
+
+		if err := batchStore.StoreBatch(ctx, batch); err != nil {
+			return written, err
+		}
+
+		for batchKey := range batch {
+			written = append(written, batchKey)
+		}
+
+		batch = make(map[K]V, p.batchSize)
+	}
+
+	return written, nil
+}
+
+// put adds or updates the entry with the provided key and moves it to the
+// front. If the cache is full, the least recently used clean entry is evicted,
+// if there is none 'false' is returned and the cache is not changed.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[K, V]) put(key K, value V, dirty bool) bool {
+	element, ok := p.index[key]
+
+	if ok {
+		p.lru.MoveToFront(element)
+	} else {
+		if p.lru.Len() >= p.maxSize && !p.evict() {
+			return false
+		}
+
+		element = p.lru.PushFront(&entry[K, V]{key: key})
+		p.index[key] = element
+	}
+
+	e := element.Value.(*entry[K, V])
+
+	if dirty {
+		if e.dirty {
+			p.stats.Coalesced++
+		} else {
+			p.dirty++
+		}
+
+		if _, ok := p.deletes[key]; ok {
+			delete(p.deletes, key)
+			p.dirty--
+			p.stats.Coalesced++
+		}
+	}
+
+	p.version++
+	e.value = value
+	e.version = p.version
+	e.dirty = dirty
+
+	return true
+}
+
+// changed marks the running loads of the provided key as outdated.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[K, V]) changed(key K) {
+	if l, ok := p.loads[key]; ok {
+		l.changed = true
+	}
+}
+
+// evict evicts the least recently used clean entry.
+// If all entries are dirty 'false' is returned.
+// This function is only used internally and does not use
+// the mutex to lock during the write access.
+func (p *Cache[K, V]) evict() bool {
+	for element := p.lru.Back(); element != nil; element = element.Prev() {
+		if e := element.Value.(*entry[K, V]); !e.dirty {
+			p.lru.Remove(element)
+			delete(p.index, e.key)
+			p.stats.Evictions++
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// check returns an error if the cache is closed.
+func (p *Cache[K, V]) check() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return &ClosedError{}
+	}
+
+	return nil
+}
+
+// signal starts a background flush, unless one is pending already.
+func (p *Cache[K, V]) signal() {
+	select {
+	case p.kick <- struct{}{}:
+	default:
+	}
+}
+
+// run flushes the dirty entries periodically or when signalled,
+// using the provided context for the flushes.
+func (p *Cache[K, V]) run(ctx context.Context, interval time.Duration) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		case <-p.kick:
+		}
+
+		_ = p.Flush(ctx)
+	}
+}
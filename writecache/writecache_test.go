@@ -0,0 +1,263 @@
+package writecache_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/piccobit/generics/writecache"
+)
+
+// gatedStore blocks the loads & batch writes until the gate is opened
+// or their context is done.
+type gatedStore struct {
+	*writecache.MemoryStore[string, int]
+	started chan struct{}
+	gate    chan struct{}
+}
+
+func (p *gatedStore) Load(ctx context.Context, key string) (int, bool, error) {
+	if err := p.wait(ctx); err != nil {
+		return 0, false, err
+	}
+
+	return p.MemoryStore.Load(ctx, key)
+}
+
+func (p *gatedStore) StoreBatch(ctx context.Context, entries map[string]int) error {
+	if err := p.wait(ctx); err != nil {
+		return err
+	}
+
+	return p.MemoryStore.StoreBatch(ctx, entries)
+}
+
+func (p *gatedStore) wait(ctx context.Context) error {
+	p.started <- struct{}{}
+
+	select {
+	case <-p.gate:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func ExampleCache_AddByID() {
+	ctx := context.Background()
+	store := writecache.NewMemoryStore[string, int]()
+
+	myCache := writecache.New[string, int](10, store)
+
+	err := myCache.AddByID(ctx, "Ferrari", 400)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Stored: %t\n", store.Contains("Ferrari"))
+
+	store.SetError(errors.New("store unavailable"))
+
+	err = myCache.AddByID(ctx, "Porsche", 300)
+	fmt.Printf("Error: %v, Cached: %t\n", err, myCache.Contains("Porsche"))
+	// Output:
+	// Stored: true
+	// Error: store unavailable, Cached: false
+}
+
+func ExampleCache_Get() {
+	ctx := context.Background()
+	store := writecache.NewMemoryStore[string, int]()
+	_ = store.Store(ctx, "Ferrari", 400)
+
+	myCache := writecache.New[string, int](10, store)
+
+	for i := 0; i < 2; i++ {
+		value, ok, err := myCache.Get(ctx, "Ferrari")
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+		}
+
+		fmt.Printf("Get: %d %t\n", value, ok)
+	}
+
+	stats := myCache.Stats()
+	fmt.Printf("Hits: %d, Misses: %d, Loads: %d\n", stats.Hits, stats.Misses, stats.Loads)
+	// Output:
+	// Get: 400 true
+	// Get: 400 true
+	// Hits: 1, Misses: 1, Loads: 1
+}
+
+func ExampleWithWriteBehind() {
+	ctx := context.Background()
+	store := writecache.NewMemoryStore[string, int]()
+
+	myCache := writecache.New[string, int](10, store, writecache.WithWriteBehind(time.Hour, 100))
+
+	for _, horsepower := range []int{400, 420, 450} {
+		_ = myCache.AddByID(ctx, "Ferrari", horsepower)
+	}
+
+	_ = myCache.AddByID(ctx, "Porsche", 300)
+
+	fmt.Printf("Stored: %t, Dirty: %d\n", store.Contains("Ferrari"), myCache.Stats().Dirty)
+
+	err := myCache.Flush(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	value, _, _ := store.Load(ctx, "Ferrari")
+	storeStats := store.Stats()
+	fmt.Printf("Stored: %d, Batches: %d, Stores: %d\n", value, storeStats.Batches, storeStats.Stores)
+
+	stats := myCache.Stats()
+	fmt.Printf("Writes: %d, Coalesced: %d, Dirty: %d\n", stats.Writes, stats.Coalesced, stats.Dirty)
+
+	err = myCache.Close(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+	// Output:
+	// Stored: false, Dirty: 2
+	// Stored: 450, Batches: 1, Stores: 2
+	// Writes: 4, Coalesced: 2, Dirty: 0
+}
+
+func ExampleCache_Flush() {
+	ctx := context.Background()
+	store := writecache.NewMemoryStore[string, int]()
+
+	myCache := writecache.New[string, int](2, store, writecache.WithWriteBehind(time.Hour, 100))
+
+	_ = myCache.AddByID(ctx, "Ferrari", 400)
+	_ = myCache.AddByID(ctx, "Porsche", 300)
+
+	// The cache is full of dirty entries, so they are flushed before
+	// one of them is evicted.
+	_ = myCache.AddByID(ctx, "Trabant", 26)
+
+	stats := myCache.Stats()
+	fmt.Printf("Stored: %t %t, Evictions: %d, Dirty: %d\n",
+		store.Contains("Ferrari"), store.Contains("Porsche"), stats.Evictions, stats.Dirty)
+
+	// Dirty entries stay dirty if the store fails.
+	store.SetError(errors.New("store unavailable"))
+
+	_ = myCache.Remove(ctx, "Ferrari")
+	_ = myCache.Remove(ctx, "Porsche")
+
+	err := myCache.Flush(ctx)
+	fmt.Printf("Error: %v, Dirty: %d\n", err, myCache.Stats().Dirty)
+
+	_, ok, _ := myCache.Get(ctx, "Porsche")
+	fmt.Printf("Get: %t\n", ok)
+
+	store.SetError(nil)
+
+	err = myCache.Close(ctx)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Stored: %t %t %t\n", store.Contains("Ferrari"), store.Contains("Porsche"), store.Contains("Trabant"))
+	// Output:
+	// Stored: true true, Evictions: 1, Dirty: 1
+	// Error: store unavailable, Dirty: 3
+	// Get: false
+	// Stored: false false true
+}
+
+func ExampleCache_Close() {
+	ctx := context.Background()
+	store := writecache.NewMemoryStore[string, int]()
+
+	myCache := writecache.New[string, int](100, store, writecache.WithWriteBehind(time.Hour, 10))
+
+	// Reaching the batch size starts a background flush.
+	for i := 0; i < 10; i++ {
+		_ = myCache.AddByID(ctx, fmt.Sprintf("car-%d", i), i)
+	}
+
+	for myCache.Stats().Dirty > 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	fmt.Printf("Batches: %d\n", store.Stats().Batches)
+
+	_ = myCache.Close(ctx)
+
+	fmt.Printf("Error: %v\n", myCache.AddByID(ctx, "car-10", 10))
+	// Output:
+	// Batches: 1
+	// Error: Closed error
+}
+
+func ExampleCache_Close_timeout() {
+	store := &gatedStore{
+		MemoryStore: writecache.NewMemoryStore[string, int](),
+		started:     make(chan struct{}, 1),
+		gate:        make(chan struct{}),
+	}
+
+	myCache := writecache.New[string, int](10, store, writecache.WithWriteBehind(time.Hour, 1))
+
+	// Reaching the batch size starts a background flush, which hangs.
+	_ = myCache.AddByID(context.Background(), "Ferrari", 400)
+
+	<-store.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := myCache.Close(ctx)
+	fmt.Printf("Error: %v, Stored: %t\n", err, store.Contains("Ferrari"))
+	// Output:
+	// Error: context deadline exceeded, Stored: false
+}
+
+func ExampleCache_Get_concurrent() {
+	ctx := context.Background()
+	store := &gatedStore{
+		MemoryStore: writecache.NewMemoryStore[string, int](),
+		started:     make(chan struct{}, 1),
+		gate:        make(chan struct{}),
+	}
+
+	_ = store.Store(ctx, "Ferrari", 400)
+
+	myCache := writecache.New[string, int](10, store, writecache.WithWriteBehind(time.Hour, 100))
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		_, _, _ = myCache.Get(ctx, "Ferrari")
+	}()
+
+	<-store.started
+
+	// A write of another key doesn't outdate the running load.
+	_ = myCache.AddByID(ctx, "Porsche", 300)
+
+	close(store.gate)
+	wg.Wait()
+
+	value, ok, err := myCache.Get(ctx, "Ferrari")
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "ERROR: %s\n", err.Error())
+	}
+
+	fmt.Printf("Get: %d %t, Loads: %d\n", value, ok, store.Stats().Loads)
+
+	_ = myCache.Close(ctx)
+	// Output:
+	// Get: 400 true, Loads: 1
+}